@@ -0,0 +1,113 @@
+package ecs
+
+import "github.com/PieterD/boevig/rang"
+
+// Count returns the number of entities the search would yield.
+// A search on a single component or index is answered from its size, and one whose sources
+// are all bitmaps, like components and equality indexes, by intersecting them natively.
+// Like Done, it panics on errors from fallible sequences.
+func (b *SearchBuilder) Count() int {
+	if n, ok := b.countBitmaps(); ok {
+		return n
+	}
+	n := 0
+	for _, err := range b.ids() {
+		if err != nil {
+			panic(err)
+		}
+		n++
+		if n == b.limit {
+			break
+		}
+	}
+	return n
+}
+
+// Exists reports whether the search yields any entity, stopping at the first one.
+func (b *SearchBuilder) Exists() bool {
+	if b.simple() {
+		return b.sources[0].estimate() > 0
+	}
+	for _, err := range b.ids() {
+		if err != nil {
+			panic(err)
+		}
+		return true
+	}
+	return false
+}
+
+// simple reports whether the search is a single source that knows its exact size.
+func (b *SearchBuilder) simple() bool {
+	return len(b.sources) == 1 && b.sources[0].exact() && b.unbounded()
+}
+
+func (b *SearchBuilder) unbounded() bool {
+	return b.order == nil && b.limit == 0 && b.after == nil
+}
+
+// countBitmaps counts without iterating, if the search is simple or all its sources are bitmaps.
+func (b *SearchBuilder) countBitmaps() (int, bool) {
+	if b.simple() {
+		return b.sources[0].estimate(), true
+	}
+	if len(b.sources) == 0 || !b.unbounded() {
+		return 0, false
+	}
+	var bitmaps []*rang.Bitmap[EntityID]
+	for _, p := range planSources(b.sources) {
+		ids, ok := p.source.bitmap()
+		if !ok {
+			return 0, false
+		}
+		bitmaps = append(bitmaps, ids)
+	}
+	ids := bitmaps[0]
+	for _, other := range bitmaps[1:] {
+		ids = ids.And(other)
+	}
+	return ids.Len(), true
+}
+
+// CountBy counts the entities in the search by a key derived from their C component.
+// Entities without a C component are skipped.
+func CountBy[K comparable, C Component, CP PTRContract[C]](b *SearchBuilder, key func(C) K) map[K]int {
+	counts := make(map[K]int)
+	var c C
+	for id, err := range b.ids() {
+		if err != nil {
+			panic(err)
+		}
+		if !b.db.Get(id, CP(&c)) {
+			continue
+		}
+		counts[key(c)]++
+	}
+	return counts
+}
+
+// GroupBy groups the entities in the search by a key derived from their C component.
+// Entities without a C component are skipped.
+func GroupBy[K comparable, C Component, CP PTRContract[C]](b *SearchBuilder, key func(C) K) map[K][]EntityID {
+	groups := make(map[K][]EntityID)
+	var c C
+	for id, err := range b.ids() {
+		if err != nil {
+			panic(err)
+		}
+		if !b.db.Get(id, CP(&c)) {
+			continue
+		}
+		k := key(c)
+		groups[k] = append(groups[k], id)
+	}
+	return groups
+}
+
+// CountByIndex returns the number of entities for every value in an equality index,
+// read directly from the index.
+func CountByIndex[T comparable](db *DB, indexName string) map[T]int {
+	var zero T
+	page := getPageG(db.indices, indexName, zero)
+	return page.CountsG()
+}
@@ -0,0 +1,46 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchBuilder_Count(t *testing.T) {
+	db, _ := dbDefaults()
+	var str TestComponentString
+	var num TestComponentNum
+	var boo TestComponentBool
+	require.Equal(t, 3, db.Search().Components(&str).Count())
+	require.Equal(t, 1, db.Search().Components(&str, &num).Count())
+	require.Equal(t, 2, db.Search().Index(EQ("test_bool", true)).Count())
+	require.Equal(t, 1, db.Search().Index(EQ("test_bool", true), EQ("test_num", 1)).Count())
+	require.Equal(t, 0, db.Search().Index(EQ("test_str", "nope")).Count())
+	require.Equal(t, 0, db.Search().Count())
+	var idx TestComponentIndex
+	require.Equal(t, 2, db.Search().Components(&idx).Index(EQ("test_bool", true)).Count())
+	require.Equal(t, 1, db.Search().Components(&idx, &num).Index(EQ("test_bool", true)).Count())
+	require.Equal(t, 2, db.Search().Components(&idx).Index(Where("test_num", func(n int) bool {
+		return n > 1
+	})).Count())
+	q := db.CachedQuery(db.Search().Components(&idx))
+	require.Equal(t, 1, db.Search().Cached(q).Index(EQ("test_num", 3)).Count())
+	require.True(t, db.Search().Components(&boo).Exists())
+	require.True(t, db.Search().Components(&str, &num).Exists())
+	require.False(t, db.Search().Components(&boo, &num).Exists())
+	require.False(t, db.Search().Index(EQ("test_str", "nope")).Exists())
+}
+
+func TestCountBy(t *testing.T) {
+	db, _ := dbDefaults()
+	var idx TestComponentIndex
+	counts := CountBy(db.Search().Components(&idx), func(c TestComponentIndex) bool {
+		return c.Bool
+	})
+	require.Equal(t, map[bool]int{true: 2, false: 1}, counts)
+	groups := GroupBy(db.Search().Components(&idx), func(c TestComponentIndex) bool {
+		return c.Bool
+	})
+	require.Equal(t, map[bool][]EntityID{true: {1, 5}, false: {3}}, groups)
+	require.Equal(t, map[bool]int{true: 2, false: 1}, CountByIndex[bool](db, "test_bool"))
+}
@@ -0,0 +1,100 @@
+package ecs
+
+import (
+	"fmt"
+	"iter"
+
+	"github.com/PieterD/boevig/rang"
+)
+
+// CachedQuery keeps the result of a search up to date as entities change,
+// so it does not have to be intersected again every time it is used.
+type CachedQuery struct {
+	db            *DB
+	sources       []searchSource
+	componentPtrs []Component
+	ids           *rang.Bitmap[EntityID]
+}
+
+// CachedQuery registers the search in b with the DB.
+// Searches containing raw sequences cannot be cached, since the DB cannot tell when they change.
+func (db *DB) CachedQuery(b *SearchBuilder) *CachedQuery {
+	for _, source := range b.sources {
+		if _, ok := source.(seqSource); ok {
+			panic(fmt.Errorf("caching query: raw sequences cannot be cached"))
+		}
+	}
+	query := &CachedQuery{
+		db:            db,
+		sources:       b.sources,
+		componentPtrs: b.componentPtrs,
+		ids:           rang.NewBitmap[EntityID](),
+	}
+	for sid, err := range b.seekSeq() {
+		if err != nil {
+			panic(fmt.Errorf("caching query: %w", err))
+		}
+		query.ids.Add(sid.Value())
+	}
+	db.queries[query] = struct{}{}
+	return query
+}
+
+// Close stops the DB from updating the query.
+func (q *CachedQuery) Close() {
+	delete(q.db.queries, q)
+}
+
+func (q *CachedQuery) Len() int {
+	return q.ids.Len()
+}
+
+func (q *CachedQuery) Contains(id EntityID) bool {
+	return q.ids.Contains(id)
+}
+
+// Done yields the matching entities in EntityID order, filling the component pointers of the search.
+// The DB may be modified while iterating.
+func (q *CachedQuery) Done() iter.Seq[EntityID] {
+	return func(yield func(EntityID) bool) {
+		var from EntityID
+		for {
+			id, ok := q.ids.Next(from)
+			if !ok {
+				return
+			}
+			from = id + 1
+			if !q.db.components.Get(id, q.componentPtrs...) {
+				panic(fmt.Errorf("cached query get %v: ID appeared in query, not in component pages", id))
+			}
+			if !yield(id) {
+				return
+			}
+		}
+	}
+}
+
+func (q *CachedQuery) SeekSeq() iter.Seq[rang.Seekable[EntityID]] {
+	return q.ids.SeekSeq()
+}
+
+func (q *CachedQuery) update(id EntityID) {
+	if q.matches(id) {
+		q.ids.Add(id)
+		return
+	}
+	q.ids.Remove(id)
+}
+
+func (q *CachedQuery) matches(id EntityID) bool {
+	for _, source := range q.sources {
+		ok, err := source.contains(id)
+		if err != nil {
+			panic(fmt.Errorf("updating cached query for %v: %w", id, err))
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
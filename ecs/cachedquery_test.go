@@ -0,0 +1,81 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/PieterD/boevig/rang"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedQuery(t *testing.T) {
+	t.Run("initial result", func(t *testing.T) {
+		db, _ := dbDefaults()
+		var num TestComponentNum
+		var idx TestComponentIndex
+		q := db.CachedQuery(db.Search().Components(&num, &idx))
+		require.Equal(t, 2, q.Len())
+		var nums []int
+		ids := rang.ToSlice(q.Done())
+		for range q.Done() {
+			nums = append(nums, num.Int)
+		}
+		require.Equal(t, []EntityID{1, 3}, ids)
+		require.Equal(t, []int{1, 3}, nums)
+	})
+	t.Run("follows set, unset and remove", func(t *testing.T) {
+		db, _ := dbDefaults()
+		var idx TestComponentIndex
+		q := db.CachedQuery(db.Search().Components(&idx).Index(EQ("test_bool", true)))
+		require.Equal(t, []EntityID{1, 5}, rang.ToSlice(q.Done()))
+		db.Set(3, TestComponentIndex{String: "indexed_string_3", Num: 3, Bool: true})
+		require.Equal(t, []EntityID{1, 3, 5}, rang.ToSlice(q.Done()))
+		db.Set(1, TestComponentIndex{String: "indexed_string_1", Num: 1, Bool: false})
+		require.Equal(t, []EntityID{3, 5}, rang.ToSlice(q.Done()))
+		db.Unset(5, TestComponentIndex{})
+		require.Equal(t, []EntityID{3}, rang.ToSlice(q.Done()))
+		db.Remove(3)
+		require.Equal(t, []EntityID(nil), rang.ToSlice(q.Done()))
+		id := db.NewEntity(TestComponentIndex{Bool: true})
+		require.Equal(t, []EntityID{id}, rang.ToSlice(q.Done()))
+	})
+	t.Run("modify while iterating", func(t *testing.T) {
+		db := New()
+		for i := 0; i < 10; i++ {
+			db.NewEntity(TestComponentNum{Int: i})
+		}
+		var num TestComponentNum
+		q := db.CachedQuery(db.Search().Components(&num))
+		var seen []EntityID
+		for id := range q.Done() {
+			seen = append(seen, id)
+			db.Unset(id, TestComponentNum{})
+		}
+		require.Len(t, seen, 10)
+		require.Equal(t, 0, q.Len())
+	})
+	t.Run("compose", func(t *testing.T) {
+		db, _ := dbDefaults()
+		var str TestComponentString
+		q := db.CachedQuery(db.Search().Components(&str))
+		var num TestComponentNum
+		ids := rang.ToSlice(db.Search().Components(&num).Cached(q).Done())
+		require.Equal(t, []EntityID{1}, ids)
+		ids = rang.ToSlice(db.Search().Components(&num).SeekSeq(q.SeekSeq()).Done())
+		require.Equal(t, []EntityID{1}, ids)
+	})
+	t.Run("close", func(t *testing.T) {
+		db, _ := dbDefaults()
+		var str TestComponentString
+		q := db.CachedQuery(db.Search().Components(&str))
+		q.Close()
+		db.NewEntity(TestComponentString{})
+		require.Equal(t, 3, q.Len())
+	})
+	t.Run("raw sequences rejected", func(t *testing.T) {
+		db, _ := dbDefaults()
+		var str TestComponentString
+		require.Panics(t, func() {
+			db.CachedQuery(db.Search().SeekSeq(db.SearchComponents(&str)))
+		})
+	})
+}
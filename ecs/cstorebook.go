@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"iter"
 	"reflect"
+	"sort"
 
 	"github.com/PieterD/boevig/rang"
 )
@@ -13,6 +14,11 @@ type cStorePage interface {
 	Remove(id EntityID)
 	Get(id EntityID, cPtr Component) bool
 	SeekSeq() iter.Seq[rang.Seekable[EntityID]]
+	ReverseSeekSeq() iter.Seq[rang.Seekable[EntityID]]
+	Cursor() rang.Cursor[EntityID]
+	Bitmap() *rang.Bitmap[EntityID]
+	Len() int
+	Has(id EntityID) bool
 }
 
 type cStoreBook struct {
@@ -51,12 +57,31 @@ func (cb *cStoreBook) Get(id EntityID, componentPtrs ...Component) bool {
 }
 
 func (cb *cStoreBook) All(componentPtrs ...Component) iter.Seq[rang.Seekable[EntityID]] {
+	return func(yield func(rang.Seekable[EntityID]) bool) {
+		for sid, err := range cb.AllE(componentPtrs...) {
+			if err != nil {
+				panic(err)
+			}
+			if !yield(sid) {
+				return
+			}
+		}
+	}
+}
+
+func (cb *cStoreBook) AllE(componentPtrs ...Component) iter.Seq2[rang.Seekable[EntityID], error] {
 	o := rang.NewOrdered[EntityID](EntityID.Less)
-	return o.SeekIterator(func(start *EntityID) iter.Seq[EntityID] {
-		return func(yield func(EntityID) bool) {
-			var seqs []iter.Seq[rang.Seekable[EntityID]]
+	return o.SeekIteratorE(func(start *EntityID) iter.Seq2[EntityID, error] {
+		return func(yield func(EntityID, error) bool) {
+			var pages []cStorePage
 			for _, componentPtr := range componentPtrs {
-				page := cb.getPage(componentPtr)
+				pages = append(pages, cb.getPage(componentPtr))
+			}
+			sort.SliceStable(pages, func(i, j int) bool {
+				return pages[i].Len() < pages[j].Len()
+			})
+			var seqs []iter.Seq[rang.Seekable[EntityID]]
+			for _, page := range pages {
 				seqs = append(seqs, page.SeekSeq())
 			}
 			for sid := range o.Intersect(seqs...) {
@@ -69,10 +94,11 @@ func (cb *cStoreBook) All(componentPtrs ...Component) iter.Seq[rang.Seekable[Ent
 					page := cb.getPage(componentPtr)
 					ok := page.Get(sid.Value(), componentPtr)
 					if !ok {
-						panic(fmt.Errorf("page get %v component %T: ID appeared in union, not in page", sid.Value(), componentPtr))
+						yield(0, fmt.Errorf("page get %v component %T: ID appeared in union, not in page", sid.Value(), componentPtr))
+						return
 					}
 				}
-				if !yield(sid.Value()) {
+				if !yield(sid.Value(), nil) {
 					return
 				}
 			}
@@ -1,8 +1,11 @@
 package ecs
 
 import (
+	"math/rand"
 	"testing"
 
+	"github.com/PieterD/boevig/rang"
+	"github.com/PieterD/boevig/rang/rangtest"
 	"github.com/stretchr/testify/require"
 )
 
@@ -145,6 +148,55 @@ func TestCStoreBook_All(t *testing.T) {
 	})
 }
 
+func TestCStorePageG_Cursor(t *testing.T) {
+	book, tcs, _, _ := cStoreBookDefaults()
+	page := book.getPage(&tcs)
+	c := page.Cursor()
+	require.True(t, c.Seek(2))
+	require.Equal(t, EntityID(3), c.Value())
+	require.True(t, c.Seek(0))
+	require.Equal(t, EntityID(3), c.Value())
+	page.Remove(5)
+	require.True(t, c.Next())
+	require.Equal(t, EntityID(7), c.Value())
+	require.False(t, c.Next())
+	require.False(t, c.Seek(1))
+
+	c = page.Cursor()
+	var ids []EntityID
+	for ok := c.Next(); ok; ok = c.Next() {
+		ids = append(ids, c.Value())
+	}
+	require.Equal(t, []EntityID{1, 3, 7}, ids)
+}
+
+func TestCStorePageG_Bitmap(t *testing.T) {
+	book, tcs, tcn, _ := cStoreBookDefaults()
+	strings, nums := book.getPage(&tcs), book.getPage(&tcn)
+	require.Equal(t, []EntityID{1, 3, 5, 7}, rang.ToSlice(strings.Bitmap().Values()))
+	require.Equal(t, []EntityID{3, 7}, rang.ToSlice(strings.Bitmap().And(nums.Bitmap()).Values()))
+	book.Remove(3)
+	require.Equal(t, []EntityID{1, 5, 7}, rang.ToSlice(strings.Bitmap().Values()))
+	require.Equal(t, 3, strings.Len())
+	require.False(t, strings.Has(3))
+	book.Add(3, TestComponentString{String: "c3"})
+	book.Add(3, TestComponentString{String: "c3 again"})
+	require.Equal(t, 4, strings.Len())
+}
+
+func TestCStorePageG_SeekContract(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, size := range []int{0, 10, 1000} {
+		page := newCStorePageG[TestComponentNum]()
+		want := rangtest.RandomSet[EntityID](rnd, size, 3*size)
+		for _, id := range want {
+			page.AddG(id, TestComponentNum{Int: int(id)})
+		}
+		require.NoError(t, rangtest.CheckSeekSeq(page.SeekSeq(), want, rnd), "size %d", size)
+		require.NoError(t, rangtest.CheckCursor(page.Cursor, want, rnd), "size %d", size)
+	}
+}
+
 func cStoreBookDefaults() (*cStoreBook, TestComponentString, TestComponentNum, TestComponentFloat) {
 	book := newCStoreBook()
 	book.Add(1, TestComponentString{String: "c1"})
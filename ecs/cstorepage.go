@@ -8,8 +8,11 @@ import (
 	"github.com/google/btree"
 )
 
+// cStorePageG keeps membership in a bitmap next to the components,
+// so it can be searched like any other set of IDs.
 type cStorePageG[T Component, TP PTRContract[T]] struct {
 	tree *btree.BTreeG[tuple[T]]
+	ids  *rang.Bitmap[EntityID]
 }
 
 type tuple[VAL any] struct {
@@ -26,6 +29,7 @@ func newCStorePageG[T Component, TP PTRContract[T]]() *cStorePageG[T, TP] {
 	}
 	return &cStorePageG[T, TP]{
 		tree: btree.NewG(5, less),
+		ids:  rang.NewBitmap[EntityID](),
 	}
 }
 
@@ -39,10 +43,12 @@ func (cp *cStorePageG[T, TP]) Add(id EntityID, iv Component) {
 
 func (cp *cStorePageG[T, TP]) AddG(id EntityID, v T) {
 	cp.tree.ReplaceOrInsert(tuple[T]{ID: id, Val: v})
+	cp.ids.Add(id)
 }
 
 func (cp *cStorePageG[T, TP]) Remove(id EntityID) {
 	cp.tree.Delete(tuple[T]{ID: id})
+	cp.ids.Remove(id)
 }
 
 func (cp *cStorePageG[T, TP]) Get(id EntityID, iv Component) bool {
@@ -68,26 +74,28 @@ func (cp *cStorePageG[T, TP]) GetG(id EntityID) (T, bool) {
 }
 
 func (cp *cStorePageG[T, TP]) SeekSeq() iter.Seq[rang.Seekable[EntityID]] {
-	idLess := func(a, b EntityID) bool {
-		return a < b
-	}
-	return rang.NewOrdered[EntityID](idLess).SeekIterator(func(start *EntityID) iter.Seq[EntityID] {
-		return func(yield func(EntityID) bool) {
-			if start == nil {
-				first, ok := cp.tree.Min()
-				if !ok {
-					return
-				}
-				start = &first.ID
-			}
-			cp.tree.AscendGreaterOrEqual(tuple[T]{ID: *start}, func(item tuple[T]) bool {
-				if !yield(item.ID) {
-					return false
-				}
-				return true
-			})
-		}
-	})
+	return cp.ids.SeekSeq()
+}
+
+func (cp *cStorePageG[T, TP]) ReverseSeekSeq() iter.Seq[rang.Seekable[EntityID]] {
+	return cp.ids.ReverseSeekSeq()
+}
+
+func (cp *cStorePageG[T, TP]) Cursor() rang.Cursor[EntityID] {
+	return cp.ids.Cursor()
+}
+
+// Bitmap returns the live set of IDs in the page, which must not be modified.
+func (cp *cStorePageG[T, TP]) Bitmap() *rang.Bitmap[EntityID] {
+	return cp.ids
+}
+
+func (cp *cStorePageG[T, TP]) Len() int {
+	return cp.ids.Len()
+}
+
+func (cp *cStorePageG[T, TP]) Has(id EntityID) bool {
+	return cp.ids.Contains(id)
 }
 
 func (cp *cStorePageG[T, TP]) AllG() iter.Seq2[EntityID, T] {
@@ -1,6 +1,7 @@
 package ecs
 
 import (
+	"fmt"
 	"iter"
 
 	"github.com/PieterD/boevig/rang"
@@ -11,6 +12,7 @@ type DB struct {
 	indices        *indexBook
 	activeEntities map[EntityID]struct{}
 	entityID       EntityID
+	queries        map[*CachedQuery]struct{}
 }
 
 func New() *DB {
@@ -18,10 +20,22 @@ func New() *DB {
 		components:     newCStoreBook(),
 		indices:        newIndexBook(),
 		activeEntities: make(map[EntityID]struct{}),
+		queries:        make(map[*CachedQuery]struct{}),
 	}
 }
 
 func (db *DB) NewEntity(components ...Component) EntityID {
+	id, err := db.TryNewEntity(components...)
+	if err != nil {
+		panic(fmt.Errorf("creating entity: %w", err))
+	}
+	return id
+}
+
+func (db *DB) TryNewEntity(components ...Component) (EntityID, error) {
+	if err := db.indices.Check(0, components...); err != nil {
+		return 0, err
+	}
 	for {
 		db.entityID++
 		if _, ok := db.activeEntities[db.entityID]; ok {
@@ -31,13 +45,21 @@ func (db *DB) NewEntity(components ...Component) EntityID {
 		break
 	}
 	db.Set(db.entityID, components...)
-	return db.entityID
+	return db.entityID, nil
 }
 
 func (db *DB) Remove(id EntityID) {
 	delete(db.activeEntities, id)
 	db.components.Remove(id)
 	db.indices.RemoveAll(id)
+	db.updateQueries(id)
+}
+
+// Clear removes every component of id, but keeps the entity.
+func (db *DB) Clear(id EntityID) {
+	db.components.Remove(id)
+	db.indices.RemoveAll(id)
+	db.updateQueries(id)
 }
 
 func (db *DB) Get(id EntityID, componentPtrs ...Component) bool {
@@ -45,28 +67,60 @@ func (db *DB) Get(id EntityID, componentPtrs ...Component) bool {
 }
 
 func (db *DB) Set(id EntityID, components ...Component) {
+	if err := db.TrySet(id, components...); err != nil {
+		panic(fmt.Errorf("setting %v: %w", id, err))
+	}
+}
+
+func (db *DB) TrySet(id EntityID, components ...Component) error {
+	if err := db.indices.Check(id, components...); err != nil {
+		return err
+	}
 	db.components.Add(id, components...)
 	for _, component := range components {
 		db.indices.Set(id, component)
 	}
+	db.updateQueries(id)
+	return nil
+}
+
+func (db *DB) Lookup(indexer LookupIndexer) (EntityID, bool) {
+	return indexer.lookup(db.indices)
 }
 
 func (db *DB) Unset(id EntityID, component Component) {
 	db.components.RemoveComponent(id, component)
 	db.indices.Remove(id, component)
+	db.updateQueries(id)
+}
+
+func (db *DB) updateQueries(id EntityID) {
+	for query := range db.queries {
+		query.update(id)
+	}
 }
 
 func (db *DB) SearchComponents(componentPtrs ...Component) iter.Seq[rang.Seekable[EntityID]] {
 	return db.components.All(componentPtrs...)
 }
 
+func (db *DB) SearchComponentsE(componentPtrs ...Component) iter.Seq2[rang.Seekable[EntityID], error] {
+	return db.components.AllE(componentPtrs...)
+}
+
 func (db *DB) SearchIndex(indexers ...Indexer) iter.Seq[rang.Seekable[EntityID]] {
 	return db.indices.Search(indexers...)
 }
 
 type SearchBuilder struct {
-	db   *DB
-	seqs []iter.Seq[rang.Seekable[EntityID]]
+	db            *DB
+	sources       []searchSource
+	componentPtrs []Component
+	order         Order
+	limit         int
+	after         *EntityID
+	afterKey      OrderKey
+	reverse       bool
 }
 
 func (db *DB) Search() *SearchBuilder {
@@ -74,28 +128,273 @@ func (db *DB) Search() *SearchBuilder {
 }
 
 func (b *SearchBuilder) Components(componentPtrs ...Component) *SearchBuilder {
-	b.seqs = append(b.seqs, b.db.SearchComponents(componentPtrs...))
+	for _, componentPtr := range componentPtrs {
+		b.sources = append(b.sources, componentSource{
+			page:         b.db.components.getPage(componentPtr),
+			componentPtr: componentPtr,
+		})
+	}
+	b.componentPtrs = append(b.componentPtrs, componentPtrs...)
 	return b
 }
 
 func (b *SearchBuilder) Index(indexers ...Indexer) *SearchBuilder {
-	b.seqs = append(b.seqs, b.db.SearchIndex(indexers...))
+	b.sources = append(b.sources, indexSource{
+		book:     b.db.indices,
+		indexers: indexers,
+	})
+	return b
+}
+
+func (b *SearchBuilder) Cached(query *CachedQuery) *SearchBuilder {
+	b.sources = append(b.sources, cachedSource{query: query})
 	return b
 }
 
 func (b *SearchBuilder) SeekSeq(i iter.Seq[rang.Seekable[EntityID]]) *SearchBuilder {
-	b.seqs = append(b.seqs, i)
+	b.sources = append(b.sources, seqSource{seq: rang.Fallible(i)})
 	return b
 }
 
-func (b *SearchBuilder) Done() iter.Seq[EntityID] {
-	if len(b.seqs) == 0 {
-		return func(yield func(EntityID) bool) {
+// SeekSeqE adds a fallible sequence to the search.
+// Its errors are yielded by DoneE, and cause Done to panic.
+func (b *SearchBuilder) SeekSeqE(i iter.Seq2[rang.Seekable[EntityID], error]) *SearchBuilder {
+	b.sources = append(b.sources, seqSource{seq: i})
+	return b
+}
+
+// OrderBy yields the results in the order of an ordered index, instead of by EntityID.
+// Entities missing from the index are not yielded.
+func (b *SearchBuilder) OrderBy(order Order) *SearchBuilder {
+	b.order = order
+	return b
+}
+
+// Reverse yields the results in descending EntityID order.
+// On searches with an OrderBy, it flips the direction of the order.
+func (b *SearchBuilder) Reverse() *SearchBuilder {
+	b.reverse = true
+	return b
+}
+
+func (b *SearchBuilder) Limit(n int) *SearchBuilder {
+	b.limit = n
+	return b
+}
+
+// After continues a search just past the given entity, for pagination.
+// With an OrderBy the entity has to still be in the index, use AfterKey otherwise.
+func (b *SearchBuilder) After(id EntityID) *SearchBuilder {
+	b.after = &id
+	b.afterKey = nil
+	return b
+}
+
+// AfterKey continues a search just past a Key from KeyOf, for pagination in OrderBy searches.
+// Searches without an OrderBy continue past its ID.
+func (b *SearchBuilder) AfterKey(key OrderKey) *SearchBuilder {
+	id := key.entityID()
+	b.after = &id
+	b.afterKey = key
+	return b
+}
+
+func (b *SearchBuilder) seekSeq() iter.Seq2[rang.Seekable[EntityID], error] {
+	return func(yield func(rang.Seekable[EntityID], error) bool) {
+		planned := planSources(b.sources)
+		if len(planned) == 0 {
+			return
+		}
+		o := rang.NewOrdered(EntityID.Less)
+		if cursors, ok := b.sourceCursors(planned); ok {
+			seq := o.CursorSeq(func() rang.Cursor[EntityID] {
+				return o.IntersectCursors(cursors...)
+			})
+			for sid := range seq {
+				if !yield(sid, nil) {
+					return
+				}
+			}
 			return
 		}
+		var seqs []iter.Seq2[rang.Seekable[EntityID], error]
+		for _, p := range planned {
+			if b.reverse {
+				seqs = append(seqs, p.source.reverseSeekSeq())
+				continue
+			}
+			seqs = append(seqs, p.source.seekSeq())
+		}
+		if b.reverse {
+			o = o.Reverse()
+		}
+		seq := seqs[0]
+		if len(seqs) > 1 {
+			seq = o.IntersectE(seqs...)
+		}
+		for sid, err := range seq {
+			if !yield(sid, err) {
+				return
+			}
+		}
+	}
+}
+
+// sourceCursors returns native cursors for the sources, if they all have one.
+// Cursors only go forwards, so reverse searches use sequences.
+func (b *SearchBuilder) sourceCursors(planned []plannedSource) ([]rang.Cursor[EntityID], bool) {
+	if b.reverse {
+		return nil, false
+	}
+	cursors := make([]rang.Cursor[EntityID], 0, len(planned))
+	for _, p := range planned {
+		cursor, ok := p.source.cursor()
+		if !ok {
+			return nil, false
+		}
+		cursors = append(cursors, cursor)
+	}
+	return cursors, true
+}
+
+// Explain returns the plan Done would use right now.
+// Sources that cannot tell their size, like SeekSeq, are estimated as UnknownEstimate.
+func (b *SearchBuilder) Explain() Plan {
+	var plan Plan
+	if order := b.ordering(); order != nil {
+		plan = append(plan, PlanStep{
+			Source:   order.String(),
+			Estimate: order.len(b.db.indices),
+		})
+	}
+	for _, planned := range planSources(b.sources) {
+		plan = append(plan, PlanStep{
+			Source:   planned.source.String(),
+			Estimate: planned.estimate,
+		})
+	}
+	return plan
+}
+
+func (b *SearchBuilder) Done() iter.Seq[EntityID] {
+	return func(yield func(EntityID) bool) {
+		for id, err := range b.DoneE() {
+			if err != nil {
+				panic(err)
+			}
+			if !yield(id) {
+				return
+			}
+		}
+	}
+}
+
+// DoneE is Done for searches that may fail, such as ones with fallible sequences.
+// After an error is yielded, the sequence stops.
+func (b *SearchBuilder) DoneE() iter.Seq2[EntityID, error] {
+	return func(yield func(EntityID, error) bool) {
+		n := 0
+		for id, err := range b.ids() {
+			if err != nil {
+				yield(0, err)
+				return
+			}
+			if !b.db.components.Get(id, b.componentPtrs...) {
+				yield(0, fmt.Errorf("search get %v: ID appeared in intersection, not in component pages", id))
+				return
+			}
+			if !yield(id, nil) {
+				return
+			}
+			n++
+			if n == b.limit {
+				return
+			}
+		}
+	}
+}
+
+func (b *SearchBuilder) ids() iter.Seq2[EntityID, error] {
+	if b.order != nil {
+		return b.orderedIDs()
+	}
+	seq := b.seekSeq()
+	return func(yield func(EntityID, error) bool) {
+		for sid, err := range seq {
+			if err != nil {
+				yield(0, err)
+				return
+			}
+			if b.after != nil && b.reverse && sid.Value() >= *b.after {
+				if *b.after == 0 {
+					return
+				}
+				sid.Seek(*b.after - 1)
+				continue
+			}
+			if b.after != nil && !b.reverse && sid.Value() <= *b.after {
+				sid.Seek(*b.after + 1)
+				continue
+			}
+			if !yield(sid.Value(), nil) {
+				return
+			}
+		}
 	}
-	if len(b.seqs) == 1 {
-		return rang.UnSeek(b.seqs[0])
+}
+
+// ordering is the OrderBy of the search, flipped by Reverse.
+func (b *SearchBuilder) ordering() Order {
+	if b.order != nil && b.reverse {
+		return b.order.reverse()
+	}
+	return b.order
+}
+
+func (b *SearchBuilder) orderedIDs() iter.Seq2[EntityID, error] {
+	return func(yield func(EntityID, error) bool) {
+		planned := planSources(b.sources)
+		order := b.ordering()
+		after := b.afterKey
+		if after == nil && b.after != nil {
+			key, ok := order.key(b.db.indices, *b.after)
+			if !ok {
+				yield(0, fmt.Errorf("search after %v: not in %v, paginate with AfterKey instead", *b.after, order))
+				return
+			}
+			after = key
+		}
+		seq, err := order.order(b.db.indices, after)
+		if err != nil {
+			yield(0, err)
+			return
+		}
+		// Every id in the order is probed, so read sequences once instead of once per id.
+		for i, p := range planned {
+			s, ok := p.source.(seqSource)
+			if !ok {
+				continue
+			}
+			if planned[i].source, err = s.collect(); err != nil {
+				yield(0, err)
+				return
+			}
+		}
+	ids:
+		for id := range seq {
+			for _, p := range planned {
+				ok, err := p.source.contains(id)
+				if err != nil {
+					yield(0, err)
+					return
+				}
+				if !ok {
+					continue ids
+				}
+			}
+			if !yield(id, nil) {
+				return
+			}
+		}
 	}
-	return rang.UnSeek(rang.NewOrdered(EntityID.Less).Intersect(b.seqs...))
 }
@@ -1,8 +1,11 @@
 package ecs
 
 import (
+	"errors"
+	"iter"
 	"testing"
 
+	"github.com/PieterD/boevig/rang"
 	"github.com/stretchr/testify/require"
 )
 
@@ -50,6 +53,20 @@ func TestDB_Get(t *testing.T) {
 	})
 }
 
+func TestDB_Clear(t *testing.T) {
+	db, ids := dbDefaults()
+	q := db.CachedQuery(db.Search().Components(&TestComponentString{}))
+	db.Clear(ids[1])
+	require.False(t, db.Get(ids[1], &TestComponentString{}))
+	require.False(t, db.Get(ids[1], &TestComponentIndex{}))
+	require.Empty(t, rang.ToSlice(db.SearchIndex(EQ("test_num", 1))))
+	require.False(t, q.Contains(ids[1]))
+	require.Equal(t, []EntityID{ids[2], ids[4]}, rang.ToSlice(q.Done()))
+	db.Set(ids[1], TestComponentNum{Int: 10})
+	require.True(t, db.Get(ids[1], &TestComponentNum{}))
+	require.NotEqual(t, ids[1], db.NewEntity())
+}
+
 func TestDB_Search(t *testing.T) {
 	type ptrs struct {
 		str TestComponentString
@@ -130,6 +147,13 @@ func TestDB_Search(t *testing.T) {
 			},
 			expectedIds: []EntityID{1, 5},
 		},
+		{
+			desc: "index - two indexes",
+			f: func(b *SearchBuilder, ptrs *ptrs) *SearchBuilder {
+				return b.Index(EQ("test_bool", true), EQ("test_num", 5))
+			},
+			expectedIds: []EntityID{5},
+		},
 		{
 			desc: "index - limited by component",
 			f: func(b *SearchBuilder, ptrs *ptrs) *SearchBuilder {
@@ -187,3 +211,289 @@ func dbDefaults() (*DB, []EntityID) {
 		TestComponentIndex{String: "indexed_string_5", Num: 5, Bool: true}))
 	return db, ids
 }
+
+type TestComponentComposite struct {
+	ComponentHeader[TestComponentComposite, *TestComponentComposite]
+	Faction string
+	Level   int
+}
+
+func (c TestComponentComposite) Index() []Indexer {
+	return []Indexer{
+		EQ2("Faction+Level", c.Faction, c.Level),
+	}
+}
+
+type TestComponentComposite3 struct {
+	ComponentHeader[TestComponentComposite3, *TestComponentComposite3]
+	Faction string
+	Level   int
+	Role    string
+}
+
+func (c TestComponentComposite3) Index() []Indexer {
+	return []Indexer{
+		EQ3("Faction+Level+Role", c.Faction, c.Level, c.Role),
+	}
+}
+
+func TestDB_SearchComposite(t *testing.T) {
+	db := New()
+	id1 := db.NewEntity(TestComponentComposite{Faction: "orc", Level: 1})
+	id2 := db.NewEntity(TestComponentComposite{Faction: "orc", Level: 2})
+	id3 := db.NewEntity(TestComponentComposite{Faction: "elf", Level: 1})
+	id4 := db.NewEntity(TestComponentComposite{Faction: "orc", Level: 1})
+	t.Run("full key", func(t *testing.T) {
+		ids := rang.ToSlice(db.Search().Index(EQ2("Faction+Level", "orc", 1)).Done())
+		require.Equal(t, []EntityID{id1, id4}, ids)
+	})
+	t.Run("prefix", func(t *testing.T) {
+		ids := rang.ToSlice(db.Search().Index(Prefix2[string, int]("Faction+Level", "orc")).Done())
+		require.Equal(t, []EntityID{id1, id2, id4}, ids)
+	})
+	t.Run("prefix seek", func(t *testing.T) {
+		db := New()
+		var want []EntityID
+		for level := -50; level < 50; level++ {
+			db.NewEntity(TestComponentComposite{Faction: "elf", Level: level})
+			want = append(want, db.NewEntity(TestComponentComposite{Faction: "orc", Level: level}))
+		}
+		db.NewEntity(TestComponentComposite{Faction: "troll", Level: -100})
+		search := db.Search().Index(Prefix2[string, int]("Faction+Level", "orc"))
+		require.Equal(t, want, rang.ToSlice(search.Done()))
+		require.Equal(t, 100, search.Count())
+		require.Equal(t, Plan{{Source: "index Faction+Level prefix {A:orc B:0} on 1 fields", Estimate: 100}}, search.Explain())
+		ids := rang.ToSlice(db.Search().Index(Prefix2[string, int]("Faction+Level", "orc")).Reverse().Limit(2).Done())
+		require.Equal(t, []EntityID{want[99], want[98]}, ids)
+		ids = rang.ToSlice(db.Search().Index(Prefix2[string, int]("Faction+Level", "dwarf")).Done())
+		require.Empty(t, ids)
+	})
+	t.Run("prefix3", func(t *testing.T) {
+		db := New()
+		a := db.NewEntity(TestComponentComposite3{Faction: "orc", Level: 1, Role: "archer"})
+		db.NewEntity(TestComponentComposite3{Faction: "orc", Level: 2, Role: "archer"})
+		b := db.NewEntity(TestComponentComposite3{Faction: "orc", Level: 1, Role: "shaman"})
+		ids := rang.ToSlice(db.Search().Index(Prefix3[string, int, string]("Faction+Level+Role", "orc", 1)).Done())
+		require.Equal(t, []EntityID{a, b}, ids)
+		ids = rang.ToSlice(db.Search().Index(EQ3("Faction+Level+Role", "orc", 1, "shaman")).Done())
+		require.Equal(t, []EntityID{b}, ids)
+		require.Panics(t, func() { Prefix("Faction+Level+Role", Tuple3[string, int, string]{}, 0) })
+	})
+	t.Run("different kind", func(t *testing.T) {
+		require.PanicsWithError(t, "fetching index Faction+Level: index exists with different kind *ecs.compositePageG[github.com/PieterD/boevig/ecs.Tuple2[string,int]]", func() {
+			db.Search().Index(Where("Faction+Level", func(v Tuple2[string, int]) bool {
+				return v.B == 1
+			})).Count()
+		})
+	})
+	t.Run("after update", func(t *testing.T) {
+		db.Set(id4, TestComponentComposite{Faction: "elf", Level: 2})
+		ids := rang.ToSlice(db.Search().Index(EQ2("Faction+Level", "orc", 1)).Done())
+		require.Equal(t, []EntityID{id1}, ids)
+		ids = rang.ToSlice(db.Search().Index(Prefix2[string, int]("Faction+Level", "elf")).Done())
+		require.Equal(t, []EntityID{id3, id4}, ids)
+	})
+}
+
+type TestComponentUnique struct {
+	ComponentHeader[TestComponentUnique, *TestComponentUnique]
+	Name string
+}
+
+func (c TestComponentUnique) Index() []Indexer {
+	return []Indexer{
+		Unique("test_unique", c.Name),
+	}
+}
+
+func TestDB_Unique(t *testing.T) {
+	t.Run("lookup", func(t *testing.T) {
+		db := New()
+		bob := db.NewEntity(TestComponentUnique{Name: "bob"})
+		alice := db.NewEntity(TestComponentUnique{Name: "alice"})
+		id, ok := db.Lookup(Unique("test_unique", "bob"))
+		require.True(t, ok)
+		require.Equal(t, bob, id)
+		id, ok = db.Lookup(Unique("test_unique", "alice"))
+		require.True(t, ok)
+		require.Equal(t, alice, id)
+		_, ok = db.Lookup(Unique("test_unique", "carol"))
+		require.False(t, ok)
+	})
+	t.Run("search", func(t *testing.T) {
+		db := New()
+		db.NewEntity(TestComponentUnique{Name: "bob"})
+		alice := db.NewEntity(TestComponentUnique{Name: "alice"})
+		ids := rang.ToSlice(db.Search().Index(Unique("test_unique", "alice")).Done())
+		require.Equal(t, []EntityID{alice}, ids)
+		ids = rang.ToSlice(db.Search().Index(Unique("test_unique", "alice")).Reverse().Done())
+		require.Equal(t, []EntityID{alice}, ids)
+	})
+	t.Run("duplicate rejected", func(t *testing.T) {
+		db := New()
+		bob := db.NewEntity(TestComponentUnique{Name: "bob"})
+		alice := db.NewEntity(TestComponentUnique{Name: "alice"})
+		err := db.TrySet(alice, TestComponentUnique{Name: "bob"})
+		require.ErrorIs(t, err, ErrUniqueViolation)
+		var c TestComponentUnique
+		require.True(t, db.Get(alice, &c))
+		require.Equal(t, "alice", c.Name)
+		_, err = db.TryNewEntity(TestComponentUnique{Name: "bob"})
+		require.ErrorIs(t, err, ErrUniqueViolation)
+		require.Panics(t, func() {
+			db.Set(alice, TestComponentUnique{Name: "bob"})
+		})
+		id, ok := db.Lookup(Unique("test_unique", "bob"))
+		require.True(t, ok)
+		require.Equal(t, bob, id)
+	})
+	t.Run("rename frees old value", func(t *testing.T) {
+		db := New()
+		bob := db.NewEntity(TestComponentUnique{Name: "bob"})
+		db.Set(bob, TestComponentUnique{Name: "bob"})
+		db.Set(bob, TestComponentUnique{Name: "robert"})
+		_, ok := db.Lookup(Unique("test_unique", "bob"))
+		require.False(t, ok)
+		alice := db.NewEntity(TestComponentUnique{Name: "bob"})
+		id, ok := db.Lookup(Unique("test_unique", "bob"))
+		require.True(t, ok)
+		require.Equal(t, alice, id)
+	})
+	t.Run("remove frees value", func(t *testing.T) {
+		db := New()
+		bob := db.NewEntity(TestComponentUnique{Name: "bob"})
+		db.Remove(bob)
+		_, ok := db.Lookup(Unique("test_unique", "bob"))
+		require.False(t, ok)
+		_, err := db.TryNewEntity(TestComponentUnique{Name: "bob"})
+		require.NoError(t, err)
+	})
+}
+
+func TestSearchBuilder_Explain(t *testing.T) {
+	db, _ := dbDefaults()
+	var str TestComponentString
+	var num TestComponentNum
+	b := db.Search().
+		Components(&str, &num).
+		Index(EQ("test_bool", false)).
+		SeekSeq(db.SearchComponents(&num))
+	plan := b.Explain()
+	require.Equal(t, Plan{
+		{Source: "index test_bool = false", Estimate: 1},
+		{Source: "component ecs.TestComponentNum", Estimate: 2},
+		{Source: "component ecs.TestComponentString", Estimate: 3},
+		{Source: "sequence", Estimate: UnknownEstimate},
+	}, plan)
+	require.Equal(t, "1. index test_bool = false (~1)\n"+
+		"2. component ecs.TestComponentNum (~2)\n"+
+		"3. component ecs.TestComponentString (~3)\n"+
+		"4. sequence (~?)", plan.String())
+	require.Equal(t, []EntityID(nil), rang.ToSlice(b.Done()))
+}
+
+func TestSearchBuilder_Reverse(t *testing.T) {
+	db, _ := dbDefaults()
+	var str TestComponentString
+	var num TestComponentNum
+	var idx TestComponentIndex
+	t.Run("component", func(t *testing.T) {
+		var strs []string
+		var ids []EntityID
+		for id := range db.Search().Components(&str).Reverse().Done() {
+			ids = append(ids, id)
+			strs = append(strs, str.String)
+		}
+		require.Equal(t, []EntityID{4, 2, 1}, ids)
+		require.Equal(t, []string{"string_4", "string_2", "string_1"}, strs)
+	})
+	t.Run("components and index", func(t *testing.T) {
+		ids := rang.ToSlice(db.Search().Components(&idx).Index(EQ("test_bool", true)).Reverse().Done())
+		require.Equal(t, []EntityID{5, 1}, ids)
+		ids = rang.ToSlice(db.Search().Components(&num, &idx).Reverse().Done())
+		require.Equal(t, []EntityID{3, 1}, ids)
+	})
+	t.Run("indexes", func(t *testing.T) {
+		ids := rang.ToSlice(db.Search().Index(EQ("test_bool", true), Where("test_num", func(n int) bool {
+			return n > 0
+		})).Reverse().Done())
+		require.Equal(t, []EntityID{5, 1}, ids)
+		ids = rang.ToSlice(db.Search().Index(EQ("test_bool", true), EQ("test_num", 5)).Reverse().Done())
+		require.Equal(t, []EntityID{5}, ids)
+	})
+	t.Run("sequence", func(t *testing.T) {
+		ids := rang.ToSlice(db.Search().SeekSeq(db.SearchComponents(&idx)).Reverse().Done())
+		require.Equal(t, []EntityID{5, 3, 1}, ids)
+	})
+	t.Run("latest n", func(t *testing.T) {
+		ids := rang.ToSlice(db.Search().Components(&idx).Reverse().Limit(2).Done())
+		require.Equal(t, []EntityID{5, 3}, ids)
+		ids = rang.ToSlice(db.Search().Components(&idx).Reverse().After(3).Done())
+		require.Equal(t, []EntityID{1}, ids)
+	})
+}
+
+func TestSearchBuilder_DoneE(t *testing.T) {
+	db, _ := dbDefaults()
+	errTest := errors.New("test error")
+	failing := rang.NewOrdered(EntityID.Less).SeekIteratorE(func(start *EntityID) iter.Seq2[EntityID, error] {
+		return func(yield func(EntityID, error) bool) {
+			for id := EntityID(1); id <= 5; id++ {
+				if start != nil && id < *start {
+					continue
+				}
+				if id == 4 {
+					yield(0, errTest)
+					return
+				}
+				if !yield(id, nil) {
+					return
+				}
+			}
+		}
+	})
+	var str TestComponentString
+	ids, err := rang.ToSliceE(db.Search().Components(&str).SeekSeqE(failing).DoneE())
+	require.ErrorIs(t, err, errTest)
+	require.Equal(t, []EntityID{1, 2}, ids)
+	require.Panics(t, func() {
+		rang.ToSlice(db.Search().Components(&str).SeekSeqE(failing).Done())
+	})
+	var idx TestComponentIndex
+	ids, err = rang.ToSliceE(db.Search().Components(&idx).SeekSeqE(failing).Limit(2).DoneE())
+	require.NoError(t, err)
+	require.Equal(t, []EntityID{1, 3}, ids)
+}
+
+func BenchmarkSearchBuilder_Done(b *testing.B) {
+	db := New()
+	for i := 0; i < 100000; i++ {
+		components := []Component{TestComponentNum{Int: i}}
+		if i%10 == 0 {
+			components = append(components, TestComponentString{String: "s"})
+		}
+		if i%3 == 0 {
+			components = append(components, TestComponentIndex{Num: i % 2})
+		}
+		db.NewEntity(components...)
+	}
+	var str TestComponentString
+	var num TestComponentNum
+	search := func() *SearchBuilder {
+		return db.Search().Components(&str, &num).Index(EQ("test_num", 1))
+	}
+	b.Run("cursors", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for range search().Done() {
+			}
+		}
+	})
+	b.Run("sequences", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for range search().Reverse().Done() {
+			}
+		}
+	})
+}
@@ -156,4 +156,17 @@ func TestDB_Example(t *testing.T) {
 		require.Equal(t, expectedIds, ids)
 		require.Equal(t, expectedMonsters, monsters)
 	})
+	t.Run("count monsters", func(t *testing.T) {
+		var monster Monster
+		// Counting a single component or index is answered without iterating.
+		require.Equal(t, 3, db.Search().Components(&monster).Count())
+		// Group counts by any key derived from a component.
+		counts := ecs.CountBy(db.Search().Components(&monster), func(m Monster) string {
+			return m.Name
+		})
+		require.Equal(t, map[string]int{"bat": 1, "rat": 1, "ghost": 1}, counts)
+		// Or read them straight from an index.
+		coords := ecs.CountByIndex[Coord](db, "Location.Coord")
+		require.Equal(t, map[Coord]int{{X: 1, Y: 1}: 1, {X: 1, Y: 2}: 2}, coords)
+	})
 }
@@ -1,6 +1,7 @@
 package ecs
 
 import (
+	"fmt"
 	"iter"
 	"reflect"
 
@@ -29,6 +30,17 @@ func (book *indexBook) Set(id EntityID, c Component) {
 	}
 }
 
+func (book *indexBook) Check(id EntityID, components ...Component) error {
+	for _, c := range components {
+		for _, index := range c.Index() {
+			if err := index.check(book, id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (book *indexBook) RemoveAll(id EntityID) {
 	for _, page := range book.pages {
 		page.Remove(id)
@@ -42,10 +54,29 @@ func (book *indexBook) Remove(id EntityID, c Component) {
 }
 
 func (book *indexBook) Search(params ...Indexer) iter.Seq[rang.Seekable[EntityID]] {
+	if seq, ok := book.searchBitmaps(params, (*rang.Bitmap[EntityID]).SeekSeq); ok {
+		return seq
+	}
 	var seqs []iter.Seq[rang.Seekable[EntityID]]
 	for _, param := range params {
 		seqs = append(seqs, param.search(book))
 	}
+	return book.intersect(rang.NewOrdered(EntityID.Less), seqs)
+}
+
+// ReverseSearch is Search in descending order.
+func (book *indexBook) ReverseSearch(params ...Indexer) iter.Seq[rang.Seekable[EntityID]] {
+	if seq, ok := book.searchBitmaps(params, (*rang.Bitmap[EntityID]).ReverseSeekSeq); ok {
+		return seq
+	}
+	var seqs []iter.Seq[rang.Seekable[EntityID]]
+	for _, param := range params {
+		seqs = append(seqs, param.reverseSearch(book))
+	}
+	return book.intersect(rang.NewOrdered(EntityID.Less).Reverse(), seqs)
+}
+
+func (book *indexBook) intersect(o *rang.Ordered[EntityID], seqs []iter.Seq[rang.Seekable[EntityID]]) iter.Seq[rang.Seekable[EntityID]] {
 	if len(seqs) == 0 {
 		return func(yield func(rang.Seekable[EntityID]) bool) {
 			return
@@ -54,7 +85,48 @@ func (book *indexBook) Search(params ...Indexer) iter.Seq[rang.Seekable[EntityID
 	if len(seqs) == 1 {
 		return seqs[0]
 	}
-	return rang.NewOrdered(EntityID.Less).Intersect(seqs...)
+	return o.Intersect(seqs...)
+}
+
+// searchBitmaps intersects the params natively when they all have a bitmap.
+func (book *indexBook) searchBitmaps(params []Indexer, seq func(*rang.Bitmap[EntityID]) iter.Seq[rang.Seekable[EntityID]]) (iter.Seq[rang.Seekable[EntityID]], bool) {
+	if len(params) < 2 {
+		return nil, false
+	}
+	for _, param := range params {
+		if _, ok := param.(bitmapIndexer); !ok {
+			return nil, false
+		}
+	}
+	return func(yield func(rang.Seekable[EntityID]) bool) {
+		ids, _ := book.Bitmap(params...)
+		for sid := range seq(ids) {
+			if !yield(sid) {
+				return
+			}
+		}
+	}, true
+}
+
+// Bitmap intersects the posting lists of the params natively, if they all have one.
+// With a single param the result may be the live posting list, and must not be modified.
+func (book *indexBook) Bitmap(params ...Indexer) (*rang.Bitmap[EntityID], bool) {
+	var ids *rang.Bitmap[EntityID]
+	for _, param := range params {
+		bitmapper, ok := param.(bitmapIndexer)
+		if !ok {
+			return nil, false
+		}
+		if ids == nil {
+			ids = bitmapper.bitmap(book)
+			continue
+		}
+		ids = ids.And(bitmapper.bitmap(book))
+	}
+	if ids == nil {
+		return rang.NewBitmap[EntityID](), true
+	}
+	return ids, true
 }
 
 func (book *indexBook) getPage(indexName string, value any) indexPage {
@@ -84,5 +156,9 @@ func getPageG[T comparable](book *indexBook, indexName string, value T) *indexPa
 		page = newIndexPageG[T]()
 		book.pages[tup] = page
 	}
-	return page.(*indexPageG[T])
+	indexPage, ok := page.(*indexPageG[T])
+	if !ok {
+		panic(fmt.Errorf("fetching index %s: index exists with different kind %T", indexName, page))
+	}
+	return indexPage
 }
@@ -0,0 +1,440 @@
+package ecs
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"math"
+	"reflect"
+
+	"github.com/PieterD/boevig/rang"
+	"github.com/google/btree"
+)
+
+// Composite is an index value made of several fields, ordered by each field in turn,
+// so it can be searched on a prefix of its fields.
+type Composite[T any] interface {
+	comparable
+	// ComparePrefix compares the first n fields with those of other, or all of them if there are fewer.
+	ComparePrefix(other T, n int) int
+}
+
+// Tuple2 is the key of a composite index on two values.
+// Any struct implementing Composite works as a key, Tuple2 and Tuple3 are just a convenience.
+type Tuple2[A, B cmp.Ordered] struct {
+	A A
+	B B
+}
+
+func (t Tuple2[A, B]) ComparePrefix(other Tuple2[A, B], n int) int {
+	if c := cmp.Compare(t.A, other.A); c != 0 || n <= 1 {
+		return c
+	}
+	return cmp.Compare(t.B, other.B)
+}
+
+// Tuple3 is the key of a composite index on three values.
+type Tuple3[A, B, C cmp.Ordered] struct {
+	A A
+	B B
+	C C
+}
+
+func (t Tuple3[A, B, C]) ComparePrefix(other Tuple3[A, B, C], n int) int {
+	if c := cmp.Compare(t.A, other.A); c != 0 || n <= 1 {
+		return c
+	}
+	if c := cmp.Compare(t.B, other.B); c != 0 || n <= 2 {
+		return c
+	}
+	return cmp.Compare(t.C, other.C)
+}
+
+// EQ2 indexes and searches a composite of two values under a single index.
+func EQ2[A, B cmp.Ordered](indexName string, a A, b B) CompositeIndexer[Tuple2[A, B]] {
+	return ORDC(indexName, Tuple2[A, B]{A: a, B: b})
+}
+
+// EQ3 indexes and searches a composite of three values under a single index.
+func EQ3[A, B, C cmp.Ordered](indexName string, a A, b B, c C) CompositeIndexer[Tuple3[A, B, C]] {
+	return ORDC(indexName, Tuple3[A, B, C]{A: a, B: b, C: c})
+}
+
+// Prefix2 searches an EQ2 index on its first value only.
+func Prefix2[A, B cmp.Ordered](indexName string, a A) PrefixIndexer[Tuple2[A, B]] {
+	return Prefix(indexName, Tuple2[A, B]{A: a}, 1)
+}
+
+// Prefix3 searches an EQ3 index on its first two values only.
+func Prefix3[A, B, C cmp.Ordered](indexName string, a A, b B) PrefixIndexer[Tuple3[A, B, C]] {
+	return Prefix(indexName, Tuple3[A, B, C]{A: a, B: b}, 2)
+}
+
+// compositeItem is an entry in a composite page, or a bound around all entries sharing
+// the first fields of Value when fields is set.
+type compositeItem[T Composite[T]] struct {
+	Value  T
+	ID     EntityID
+	fields int
+	bound  int
+}
+
+type compositePageG[T Composite[T]] struct {
+	idToValue map[EntityID]T
+	counts    map[T]int
+	tree      *btree.BTreeG[compositeItem[T]]
+}
+
+func newCompositePageG[T Composite[T]]() *compositePageG[T] {
+	less := func(a, b compositeItem[T]) bool {
+		n := math.MaxInt
+		if a.fields > 0 {
+			n = a.fields
+		}
+		if b.fields > 0 && b.fields < n {
+			n = b.fields
+		}
+		if c := a.Value.ComparePrefix(b.Value, n); c != 0 {
+			return c < 0
+		}
+		if a.bound != b.bound {
+			return a.bound < b.bound
+		}
+		return a.ID < b.ID
+	}
+	return &compositePageG[T]{
+		idToValue: make(map[EntityID]T),
+		counts:    make(map[T]int),
+		tree:      btree.NewG(32, less),
+	}
+}
+
+func (page *compositePageG[T]) Set(id EntityID, vi any) {
+	v, ok := vi.(T)
+	if !ok {
+		panic(fmt.Errorf("adding to composite index %v %+v: invalid type, got %T, want %T", id, vi, vi, v))
+	}
+	page.SetG(id, v)
+}
+
+func (page *compositePageG[T]) SetG(id EntityID, value T) {
+	page.Remove(id)
+	page.idToValue[id] = value
+	page.counts[value]++
+	page.tree.ReplaceOrInsert(compositeItem[T]{Value: value, ID: id})
+}
+
+func (page *compositePageG[T]) Remove(id EntityID) {
+	value, ok := page.idToValue[id]
+	if !ok {
+		return
+	}
+	delete(page.idToValue, id)
+	page.counts[value]--
+	if page.counts[value] == 0 {
+		delete(page.counts, value)
+	}
+	page.tree.Delete(compositeItem[T]{Value: value, ID: id})
+}
+
+func (page *compositePageG[T]) ValueG(id EntityID) (T, bool) {
+	value, ok := page.idToValue[id]
+	return value, ok
+}
+
+func (page *compositePageG[T]) SeekSeq(vi any) iter.Seq[rang.Seekable[EntityID]] {
+	v, ok := vi.(T)
+	if !ok {
+		panic(fmt.Errorf("seekseq on composite index %+v: invalid type, got %T, want %T", vi, vi, v))
+	}
+	return page.SeekSeqG(v)
+}
+
+func (page *compositePageG[T]) SeekSeqG(value T) iter.Seq[rang.Seekable[EntityID]] {
+	return rang.NewOrdered(EntityID.Less).SeekIterator(func(start *EntityID) iter.Seq[EntityID] {
+		return func(yield func(EntityID) bool) {
+			pivot := compositeItem[T]{Value: value}
+			if start != nil {
+				pivot.ID = *start
+			}
+			page.tree.AscendGreaterOrEqual(pivot, func(item compositeItem[T]) bool {
+				if item.Value != value {
+					return false
+				}
+				return yield(item.ID)
+			})
+		}
+	})
+}
+
+func (page *compositePageG[T]) ReverseSeekSeqG(value T) iter.Seq[rang.Seekable[EntityID]] {
+	return rang.NewOrdered(EntityID.Less).Reverse().SeekIterator(func(start *EntityID) iter.Seq[EntityID] {
+		return func(yield func(EntityID) bool) {
+			pivot := compositeItem[T]{Value: value, ID: ^EntityID(0)}
+			if start != nil {
+				pivot.ID = *start
+			}
+			page.tree.DescendLessOrEqual(pivot, func(item compositeItem[T]) bool {
+				if item.Value != value {
+					return false
+				}
+				return yield(item.ID)
+			})
+		}
+	})
+}
+
+// PrefixValuesG yields the distinct values sharing the first fields of value.
+// It seeks past every value instead of visiting all of its entries.
+func (page *compositePageG[T]) PrefixValuesG(value T, fields int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		pivot := compositeItem[T]{Value: value, fields: fields, bound: -1}
+		for {
+			var next compositeItem[T]
+			found := false
+			page.tree.AscendGreaterOrEqual(pivot, func(item compositeItem[T]) bool {
+				next, found = item, true
+				return false
+			})
+			if !found || next.Value.ComparePrefix(value, fields) != 0 {
+				return
+			}
+			if !yield(next.Value) {
+				return
+			}
+			pivot = compositeItem[T]{Value: next.Value, bound: 1}
+		}
+	}
+}
+
+func (page *compositePageG[T]) SeekSeqPrefixG(value T, fields int) iter.Seq[rang.Seekable[EntityID]] {
+	return func(yield func(rang.Seekable[EntityID]) bool) {
+		var seqs []iter.Seq[rang.Seekable[EntityID]]
+		for v := range page.PrefixValuesG(value, fields) {
+			seqs = append(seqs, page.SeekSeqG(v))
+		}
+		for sid := range page.union(rang.NewOrdered(EntityID.Less), seqs) {
+			if !yield(sid) {
+				return
+			}
+		}
+	}
+}
+
+func (page *compositePageG[T]) ReverseSeekSeqPrefixG(value T, fields int) iter.Seq[rang.Seekable[EntityID]] {
+	return func(yield func(rang.Seekable[EntityID]) bool) {
+		var seqs []iter.Seq[rang.Seekable[EntityID]]
+		for v := range page.PrefixValuesG(value, fields) {
+			seqs = append(seqs, page.ReverseSeekSeqG(v))
+		}
+		for sid := range page.union(rang.NewOrdered(EntityID.Less).Reverse(), seqs) {
+			if !yield(sid) {
+				return
+			}
+		}
+	}
+}
+
+func (page *compositePageG[T]) union(o *rang.Ordered[EntityID], seqs []iter.Seq[rang.Seekable[EntityID]]) iter.Seq[rang.Seekable[EntityID]] {
+	if len(seqs) == 0 {
+		return func(yield func(rang.Seekable[EntityID]) bool) {
+			return
+		}
+	}
+	if len(seqs) == 1 {
+		return seqs[0]
+	}
+	return o.Union(seqs...)
+}
+
+func (page *compositePageG[T]) LenG(value T) int {
+	return page.counts[value]
+}
+
+func (page *compositePageG[T]) LenPrefixG(value T, fields int) int {
+	n := 0
+	for v := range page.PrefixValuesG(value, fields) {
+		n += page.counts[v]
+	}
+	return n
+}
+
+func getCompositePageG[T Composite[T]](book *indexBook, indexName string, value T) *compositePageG[T] {
+	tup := indexTuple{
+		Name: indexName,
+		Type: reflect.TypeOf(value),
+	}
+	page, ok := book.pages[tup]
+	if !ok {
+		page = newCompositePageG[T]()
+		book.pages[tup] = page
+	}
+	compositePage, ok := page.(*compositePageG[T])
+	if !ok {
+		panic(fmt.Errorf("fetching composite index %s: index exists with different kind %T", indexName, page))
+	}
+	return compositePage
+}
+
+// ORDC indexes a composite value in field order, so it can be searched with Prefix as well as on equality.
+func ORDC[T Composite[T]](indexName string, value T) CompositeIndexer[T] {
+	return CompositeIndexer[T]{
+		IndexName: indexName,
+		Value:     value,
+	}
+}
+
+type CompositeIndexer[T Composite[T]] struct {
+	IndexName string
+	Value     T
+}
+
+func (cs CompositeIndexer[T]) search(book *indexBook) iter.Seq[rang.Seekable[EntityID]] {
+	page := getCompositePageG(book, cs.IndexName, cs.Value)
+	return page.SeekSeqG(cs.Value)
+}
+
+func (cs CompositeIndexer[T]) reverseSearch(book *indexBook) iter.Seq[rang.Seekable[EntityID]] {
+	page := getCompositePageG(book, cs.IndexName, cs.Value)
+	return page.ReverseSeekSeqG(cs.Value)
+}
+
+func (cs CompositeIndexer[T]) apply(book *indexBook, id EntityID) {
+	page := getCompositePageG(book, cs.IndexName, cs.Value)
+	page.SetG(id, cs.Value)
+}
+
+func (cs CompositeIndexer[T]) remove(book *indexBook, id EntityID) {
+	page := getCompositePageG(book, cs.IndexName, cs.Value)
+	page.Remove(id)
+}
+
+func (cs CompositeIndexer[T]) check(book *indexBook, id EntityID) error {
+	return nil
+}
+
+func (cs CompositeIndexer[T]) estimate(book *indexBook) int {
+	page := getCompositePageG(book, cs.IndexName, cs.Value)
+	return page.LenG(cs.Value)
+}
+
+func (cs CompositeIndexer[T]) matches(book *indexBook, id EntityID) bool {
+	page := getCompositePageG(book, cs.IndexName, cs.Value)
+	value, ok := page.ValueG(id)
+	return ok && value == cs.Value
+}
+
+func (cs CompositeIndexer[T]) String() string {
+	return fmt.Sprintf("%s = %+v (composite)", cs.IndexName, cs.Value)
+}
+
+// Prefix searches a composite index on the first fields of value, the rest of value is ignored.
+// It is search-only, and cannot be returned from Component.Index.
+func Prefix[T Composite[T]](indexName string, value T, fields int) PrefixIndexer[T] {
+	if fields < 1 {
+		panic(fmt.Errorf("prefix on index %s: invalid number of fields %d", indexName, fields))
+	}
+	return PrefixIndexer[T]{
+		IndexName: indexName,
+		Value:     value,
+		Fields:    fields,
+	}
+}
+
+type PrefixIndexer[T Composite[T]] struct {
+	IndexName string
+	Value     T
+	Fields    int
+}
+
+func (ps PrefixIndexer[T]) search(book *indexBook) iter.Seq[rang.Seekable[EntityID]] {
+	page := getCompositePageG(book, ps.IndexName, ps.Value)
+	return page.SeekSeqPrefixG(ps.Value, ps.Fields)
+}
+
+func (ps PrefixIndexer[T]) reverseSearch(book *indexBook) iter.Seq[rang.Seekable[EntityID]] {
+	page := getCompositePageG(book, ps.IndexName, ps.Value)
+	return page.ReverseSeekSeqPrefixG(ps.Value, ps.Fields)
+}
+
+func (ps PrefixIndexer[T]) estimate(book *indexBook) int {
+	page := getCompositePageG(book, ps.IndexName, ps.Value)
+	return page.LenPrefixG(ps.Value, ps.Fields)
+}
+
+func (ps PrefixIndexer[T]) matches(book *indexBook, id EntityID) bool {
+	page := getCompositePageG(book, ps.IndexName, ps.Value)
+	value, ok := page.ValueG(id)
+	return ok && value.ComparePrefix(ps.Value, ps.Fields) == 0
+}
+
+func (ps PrefixIndexer[T]) String() string {
+	return fmt.Sprintf("%s prefix %+v on %d fields", ps.IndexName, ps.Value, ps.Fields)
+}
+
+func (ps PrefixIndexer[T]) apply(book *indexBook, id EntityID) {
+	panic(fmt.Errorf("applying prefix index %s to %v: prefix indexers are search-only", ps.IndexName, id))
+}
+
+func (ps PrefixIndexer[T]) remove(book *indexBook, id EntityID) {
+	panic(fmt.Errorf("removing prefix index %s from %v: prefix indexers are search-only", ps.IndexName, id))
+}
+
+func (ps PrefixIndexer[T]) check(book *indexBook, id EntityID) error {
+	return fmt.Errorf("checking prefix index %s for %v: prefix indexers are search-only", ps.IndexName, id)
+}
+
+// Where searches an equality index for all entities whose value matches.
+// It is search-only, and cannot be returned from Component.Index.
+func Where[T comparable](indexName string, match func(T) bool) MatchIndexer[T] {
+	return MatchIndexer[T]{
+		IndexName: indexName,
+		Match:     match,
+	}
+}
+
+type MatchIndexer[T comparable] struct {
+	IndexName string
+	Match     func(T) bool
+}
+
+func (ms MatchIndexer[T]) search(book *indexBook) iter.Seq[rang.Seekable[EntityID]] {
+	var zero T
+	page := getPageG(book, ms.IndexName, zero)
+	return page.SeekSeqMatchG(ms.Match)
+}
+
+func (ms MatchIndexer[T]) estimate(book *indexBook) int {
+	var zero T
+	page := getPageG(book, ms.IndexName, zero)
+	return page.LenMatchG(ms.Match)
+}
+
+func (ms MatchIndexer[T]) matches(book *indexBook, id EntityID) bool {
+	var zero T
+	page := getPageG(book, ms.IndexName, zero)
+	value, ok := page.ValueG(id)
+	return ok && ms.Match(value)
+}
+
+func (ms MatchIndexer[T]) reverseSearch(book *indexBook) iter.Seq[rang.Seekable[EntityID]] {
+	var zero T
+	page := getPageG(book, ms.IndexName, zero)
+	return page.ReverseSeekSeqMatchG(ms.Match)
+}
+
+func (ms MatchIndexer[T]) String() string {
+	return fmt.Sprintf("%s matching %T", ms.IndexName, *new(T))
+}
+
+func (ms MatchIndexer[T]) apply(book *indexBook, id EntityID) {
+	panic(fmt.Errorf("applying match index %s to %v: match indexers are search-only", ms.IndexName, id))
+}
+
+func (ms MatchIndexer[T]) remove(book *indexBook, id EntityID) {
+	panic(fmt.Errorf("removing match index %s from %v: match indexers are search-only", ms.IndexName, id))
+}
+
+func (ms MatchIndexer[T]) check(book *indexBook, id EntityID) error {
+	return fmt.Errorf("checking match index %s for %v: match indexers are search-only", ms.IndexName, id)
+}
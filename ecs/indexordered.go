@@ -0,0 +1,310 @@
+package ecs
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"reflect"
+
+	"github.com/PieterD/boevig/rang"
+	"github.com/google/btree"
+)
+
+type orderedItem[T cmp.Ordered] struct {
+	Value T
+	ID    EntityID
+}
+
+type orderedPageG[T cmp.Ordered] struct {
+	idToValue map[EntityID]T
+	tree      *btree.BTreeG[orderedItem[T]]
+}
+
+func newOrderedPageG[T cmp.Ordered]() *orderedPageG[T] {
+	less := func(a, b orderedItem[T]) bool {
+		if a.Value != b.Value {
+			return a.Value < b.Value
+		}
+		return a.ID < b.ID
+	}
+	return &orderedPageG[T]{
+		idToValue: make(map[EntityID]T),
+		tree:      btree.NewG(32, less),
+	}
+}
+
+func (page *orderedPageG[T]) Set(id EntityID, vi any) {
+	v, ok := vi.(T)
+	if !ok {
+		panic(fmt.Errorf("adding to ordered index %v %+v: invalid type, got %T, want %T", id, vi, vi, v))
+	}
+	page.SetG(id, v)
+}
+
+func (page *orderedPageG[T]) SetG(id EntityID, value T) {
+	page.Remove(id)
+	page.idToValue[id] = value
+	page.tree.ReplaceOrInsert(orderedItem[T]{Value: value, ID: id})
+}
+
+func (page *orderedPageG[T]) Remove(id EntityID) {
+	value, ok := page.idToValue[id]
+	if !ok {
+		return
+	}
+	delete(page.idToValue, id)
+	page.tree.Delete(orderedItem[T]{Value: value, ID: id})
+}
+
+func (page *orderedPageG[T]) ValueG(id EntityID) (T, bool) {
+	value, ok := page.idToValue[id]
+	return value, ok
+}
+
+func (page *orderedPageG[T]) Len() int {
+	return page.tree.Len()
+}
+
+func (page *orderedPageG[T]) LenG(value T) int {
+	n := 0
+	page.tree.AscendGreaterOrEqual(orderedItem[T]{Value: value}, func(item orderedItem[T]) bool {
+		if item.Value != value {
+			return false
+		}
+		n++
+		return true
+	})
+	return n
+}
+
+func (page *orderedPageG[T]) SeekSeq(vi any) iter.Seq[rang.Seekable[EntityID]] {
+	v, ok := vi.(T)
+	if !ok {
+		panic(fmt.Errorf("seekseq on ordered index %+v: invalid type, got %T, want %T", vi, vi, v))
+	}
+	return page.SeekSeqG(v)
+}
+
+func (page *orderedPageG[T]) SeekSeqG(value T) iter.Seq[rang.Seekable[EntityID]] {
+	return rang.NewOrdered(EntityID.Less).SeekIterator(func(start *EntityID) iter.Seq[EntityID] {
+		return func(yield func(EntityID) bool) {
+			pivot := orderedItem[T]{Value: value}
+			if start != nil {
+				pivot.ID = *start
+			}
+			page.tree.AscendGreaterOrEqual(pivot, func(item orderedItem[T]) bool {
+				if item.Value != value {
+					return false
+				}
+				return yield(item.ID)
+			})
+		}
+	})
+}
+
+func (page *orderedPageG[T]) ReverseSeekSeqG(value T) iter.Seq[rang.Seekable[EntityID]] {
+	return rang.NewOrdered(EntityID.Less).Reverse().SeekIterator(func(start *EntityID) iter.Seq[EntityID] {
+		return func(yield func(EntityID) bool) {
+			pivot := orderedItem[T]{Value: value, ID: ^EntityID(0)}
+			if start != nil {
+				pivot.ID = *start
+			}
+			page.tree.DescendLessOrEqual(pivot, func(item orderedItem[T]) bool {
+				if item.Value != value {
+					return false
+				}
+				return yield(item.ID)
+			})
+		}
+	})
+}
+
+// OrderG yields the IDs in the index ordered by value, then by ID.
+// With after set, it starts just past that position, whether or not the entity is still there.
+func (page *orderedPageG[T]) OrderG(desc bool, after *Key[T]) iter.Seq[EntityID] {
+	return func(yield func(EntityID) bool) {
+		visitor := func(item orderedItem[T]) bool {
+			return yield(item.ID)
+		}
+		if after == nil {
+			if desc {
+				page.tree.Descend(visitor)
+				return
+			}
+			page.tree.Ascend(visitor)
+			return
+		}
+		pivot := orderedItem[T]{Value: after.Value, ID: after.ID}
+		skipPivot := func(item orderedItem[T]) bool {
+			if item == pivot {
+				return true
+			}
+			return visitor(item)
+		}
+		if desc {
+			page.tree.DescendLessOrEqual(pivot, skipPivot)
+			return
+		}
+		page.tree.AscendGreaterOrEqual(pivot, skipPivot)
+	}
+}
+
+func getOrderedPageG[T cmp.Ordered](book *indexBook, indexName string, value T) *orderedPageG[T] {
+	tup := indexTuple{
+		Name: indexName,
+		Type: reflect.TypeOf(value),
+	}
+	page, ok := book.pages[tup]
+	if !ok {
+		page = newOrderedPageG[T]()
+		book.pages[tup] = page
+	}
+	orderedPage, ok := page.(*orderedPageG[T])
+	if !ok {
+		panic(fmt.Errorf("fetching ordered index %s: index exists with different kind %T", indexName, page))
+	}
+	return orderedPage
+}
+
+// ORD indexes a value so searches can be ordered by it.
+// Searching it matches on equality, like EQ.
+func ORD[T cmp.Ordered](indexName string, value T) OrderedIndexer[T] {
+	return OrderedIndexer[T]{
+		IndexName: indexName,
+		Value:     value,
+	}
+}
+
+type OrderedIndexer[T cmp.Ordered] struct {
+	IndexName string
+	Value     T
+}
+
+func (os OrderedIndexer[T]) search(book *indexBook) iter.Seq[rang.Seekable[EntityID]] {
+	page := getOrderedPageG(book, os.IndexName, os.Value)
+	return page.SeekSeqG(os.Value)
+}
+
+func (os OrderedIndexer[T]) apply(book *indexBook, id EntityID) {
+	page := getOrderedPageG(book, os.IndexName, os.Value)
+	page.SetG(id, os.Value)
+}
+
+func (os OrderedIndexer[T]) remove(book *indexBook, id EntityID) {
+	page := getOrderedPageG(book, os.IndexName, os.Value)
+	page.Remove(id)
+}
+
+func (os OrderedIndexer[T]) check(book *indexBook, id EntityID) error {
+	return nil
+}
+
+func (os OrderedIndexer[T]) estimate(book *indexBook) int {
+	page := getOrderedPageG(book, os.IndexName, os.Value)
+	return page.LenG(os.Value)
+}
+
+func (os OrderedIndexer[T]) matches(book *indexBook, id EntityID) bool {
+	page := getOrderedPageG(book, os.IndexName, os.Value)
+	value, ok := page.ValueG(id)
+	return ok && value == os.Value
+}
+
+func (os OrderedIndexer[T]) reverseSearch(book *indexBook) iter.Seq[rang.Seekable[EntityID]] {
+	page := getOrderedPageG(book, os.IndexName, os.Value)
+	return page.ReverseSeekSeqG(os.Value)
+}
+
+func (os OrderedIndexer[T]) String() string {
+	return fmt.Sprintf("%s = %+v (ordered)", os.IndexName, os.Value)
+}
+
+// Key is the position of an entity in an ordered index.
+// Paginating with AfterKey instead of After keeps working when that entity is removed or changes value.
+type Key[T cmp.Ordered] struct {
+	Value T
+	ID    EntityID
+}
+
+func (k Key[T]) entityID() EntityID {
+	return k.ID
+}
+
+// OrderKey is a Key of any value type, as taken by AfterKey.
+type OrderKey interface {
+	entityID() EntityID
+}
+
+// KeyOf returns the position of id in an ordered index.
+func KeyOf[T cmp.Ordered](db *DB, indexName string, id EntityID) (Key[T], bool) {
+	var zero T
+	page := getOrderedPageG(db.indices, indexName, zero)
+	value, ok := page.ValueG(id)
+	if !ok {
+		return Key[T]{}, false
+	}
+	return Key[T]{Value: value, ID: id}, true
+}
+
+// Order is the iteration order of a search, built with Asc or Desc.
+type Order interface {
+	order(book *indexBook, after OrderKey) (iter.Seq[EntityID], error)
+	key(book *indexBook, id EntityID) (OrderKey, bool)
+	len(book *indexBook) int
+	reverse() Order
+	String() string
+}
+
+func Asc[T cmp.Ordered](indexName string) Order {
+	return indexOrder[T]{IndexName: indexName}
+}
+
+func Desc[T cmp.Ordered](indexName string) Order {
+	return indexOrder[T]{IndexName: indexName, Desc: true}
+}
+
+type indexOrder[T cmp.Ordered] struct {
+	IndexName string
+	Desc      bool
+}
+
+func (o indexOrder[T]) order(book *indexBook, after OrderKey) (iter.Seq[EntityID], error) {
+	var zero T
+	page := getOrderedPageG(book, o.IndexName, zero)
+	if after == nil {
+		return page.OrderG(o.Desc, nil), nil
+	}
+	key, ok := after.(Key[T])
+	if !ok {
+		return nil, fmt.Errorf("ordering by %s after %+v: invalid key type, got %T, want %T", o.IndexName, after, after, key)
+	}
+	return page.OrderG(o.Desc, &key), nil
+}
+
+func (o indexOrder[T]) key(book *indexBook, id EntityID) (OrderKey, bool) {
+	var zero T
+	page := getOrderedPageG(book, o.IndexName, zero)
+	value, ok := page.ValueG(id)
+	if !ok {
+		return nil, false
+	}
+	return Key[T]{Value: value, ID: id}, true
+}
+
+func (o indexOrder[T]) len(book *indexBook) int {
+	var zero T
+	page := getOrderedPageG(book, o.IndexName, zero)
+	return page.Len()
+}
+
+func (o indexOrder[T]) reverse() Order {
+	o.Desc = !o.Desc
+	return o
+}
+
+func (o indexOrder[T]) String() string {
+	if o.Desc {
+		return fmt.Sprintf("order by %s descending", o.IndexName)
+	}
+	return fmt.Sprintf("order by %s ascending", o.IndexName)
+}
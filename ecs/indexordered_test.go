@@ -0,0 +1,149 @@
+package ecs
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/PieterD/boevig/rang"
+	"github.com/stretchr/testify/require"
+)
+
+type TestComponentScore struct {
+	ComponentHeader[TestComponentScore, *TestComponentScore]
+	Score int
+}
+
+func (c TestComponentScore) Index() []Indexer {
+	return []Indexer{
+		ORD("test_score", c.Score),
+	}
+}
+
+func TestSearchBuilder_OrderBy(t *testing.T) {
+	newDB := func() *DB {
+		db := New()
+		db.NewEntity(TestComponentScore{Score: 30}, TestComponentBool{Bool: true})
+		db.NewEntity(TestComponentScore{Score: 10})
+		db.NewEntity(TestComponentScore{Score: 20}, TestComponentBool{Bool: true})
+		db.NewEntity(TestComponentScore{Score: 10}, TestComponentBool{Bool: true})
+		db.NewEntity(TestComponentBool{Bool: true})
+		return db
+	}
+	t.Run("ascending", func(t *testing.T) {
+		db := newDB()
+		var score TestComponentScore
+		var scores []int
+		var ids []EntityID
+		for id := range db.Search().Components(&score).OrderBy(Asc[int]("test_score")).Done() {
+			ids = append(ids, id)
+			scores = append(scores, score.Score)
+		}
+		require.Equal(t, []EntityID{2, 4, 3, 1}, ids)
+		require.Equal(t, []int{10, 10, 20, 30}, scores)
+	})
+	t.Run("descending", func(t *testing.T) {
+		db := newDB()
+		ids := rang.ToSlice(db.Search().OrderBy(Desc[int]("test_score")).Done())
+		require.Equal(t, []EntityID{1, 3, 4, 2}, ids)
+	})
+	t.Run("filtered", func(t *testing.T) {
+		db := newDB()
+		var boo TestComponentBool
+		ids := rang.ToSlice(db.Search().Components(&boo).OrderBy(Asc[int]("test_score")).Done())
+		require.Equal(t, []EntityID{4, 3, 1}, ids)
+	})
+	t.Run("limit and after", func(t *testing.T) {
+		db := newDB()
+		ids := rang.ToSlice(db.Search().OrderBy(Desc[int]("test_score")).Limit(2).Done())
+		require.Equal(t, []EntityID{1, 3}, ids)
+		ids = rang.ToSlice(db.Search().OrderBy(Desc[int]("test_score")).After(3).Limit(2).Done())
+		require.Equal(t, []EntityID{4, 2}, ids)
+		ids = rang.ToSlice(db.Search().OrderBy(Asc[int]("test_score")).After(4).Done())
+		require.Equal(t, []EntityID{3, 1}, ids)
+		var boo TestComponentBool
+		ids = rang.ToSlice(db.Search().Components(&boo).After(3).Done())
+		require.Equal(t, []EntityID{4, 5}, ids)
+		require.Equal(t, 2, db.Search().Components(&boo).Limit(2).Count())
+	})
+	t.Run("reverse", func(t *testing.T) {
+		db := newDB()
+		ids := rang.ToSlice(db.Search().OrderBy(Asc[int]("test_score")).Reverse().Done())
+		require.Equal(t, []EntityID{1, 3, 4, 2}, ids)
+		ids = rang.ToSlice(db.Search().OrderBy(Desc[int]("test_score")).Reverse().After(4).Done())
+		require.Equal(t, []EntityID{3, 1}, ids)
+		ids = rang.ToSlice(db.Search().Index(ORD("test_score", 10)).Reverse().Done())
+		require.Equal(t, []EntityID{4, 2}, ids)
+		plan := db.Search().OrderBy(Asc[int]("test_score")).Reverse().Explain()
+		require.Equal(t, "order by test_score descending", plan[0].Source)
+	})
+	t.Run("seek seq read once", func(t *testing.T) {
+		db := newDB()
+		reads := 0
+		var boo TestComponentBool
+		seq := func(yield func(rang.Seekable[EntityID]) bool) {
+			reads++
+			for sid := range db.SearchComponents(&boo) {
+				if !yield(sid) {
+					return
+				}
+			}
+		}
+		ids := rang.ToSlice(db.Search().SeekSeq(seq).OrderBy(Asc[int]("test_score")).Done())
+		require.Equal(t, []EntityID{4, 3, 1}, ids)
+		require.Equal(t, 1, reads)
+		errSeq := errors.New("sequence error")
+		_, err := rang.ToSliceE(db.Search().SeekSeqE(rang.Fallible(seq)).SeekSeqE(func(yield func(rang.Seekable[EntityID], error) bool) {
+			yield(rang.Seekable[EntityID]{}, errSeq)
+		}).OrderBy(Asc[int]("test_score")).DoneE())
+		require.ErrorIs(t, err, errSeq)
+	})
+	t.Run("after key", func(t *testing.T) {
+		db := newDB()
+		page := rang.ToSlice(db.Search().OrderBy(Desc[int]("test_score")).Limit(2).Done())
+		require.Equal(t, []EntityID{1, 3}, page)
+		key, ok := KeyOf[int](db, "test_score", 3)
+		require.True(t, ok)
+		require.Equal(t, Key[int]{Value: 20, ID: 3}, key)
+		db.Remove(3)
+		ids := rang.ToSlice(db.Search().OrderBy(Desc[int]("test_score")).AfterKey(key).Done())
+		require.Equal(t, []EntityID{4, 2}, ids)
+		_, err := rang.ToSliceE(db.Search().OrderBy(Desc[int]("test_score")).After(3).DoneE())
+		require.ErrorContains(t, err, "paginate with AfterKey")
+		_, ok = KeyOf[int](db, "test_score", 3)
+		require.False(t, ok)
+	})
+	t.Run("after key rescored", func(t *testing.T) {
+		db := newDB()
+		key, _ := KeyOf[int](db, "test_score", 4)
+		db.Set(4, TestComponentScore{Score: 5})
+		ids := rang.ToSlice(db.Search().OrderBy(Asc[int]("test_score")).AfterKey(key).Done())
+		require.Equal(t, []EntityID{3, 1}, ids)
+		ids = rang.ToSlice(db.Search().OrderBy(Asc[int]("test_score")).After(4).Done())
+		require.Equal(t, []EntityID{2, 3, 1}, ids)
+		ids = rang.ToSlice(db.Search().Components(&TestComponentBool{}).AfterKey(key).Done())
+		require.Equal(t, []EntityID{5}, ids)
+		_, err := rang.ToSliceE(db.Search().OrderBy(Asc[int]("test_score")).AfterKey(Key[string]{ID: 1}).DoneE())
+		require.ErrorContains(t, err, "invalid key type")
+	})
+	t.Run("follows updates", func(t *testing.T) {
+		db := newDB()
+		db.Set(2, TestComponentScore{Score: 40})
+		db.Unset(1, TestComponentScore{})
+		ids := rang.ToSlice(db.Search().OrderBy(Desc[int]("test_score")).Done())
+		require.Equal(t, []EntityID{2, 3, 4}, ids)
+	})
+	t.Run("equality search", func(t *testing.T) {
+		db := newDB()
+		ids := rang.ToSlice(db.Search().Index(ORD("test_score", 10)).Done())
+		require.Equal(t, []EntityID{2, 4}, ids)
+	})
+	t.Run("explain", func(t *testing.T) {
+		db := newDB()
+		var boo TestComponentBool
+		plan := db.Search().Components(&boo).OrderBy(Desc[int]("test_score")).Explain()
+		require.Equal(t, Plan{
+			{Source: "order by test_score descending", Estimate: 4},
+			{Source: "component ecs.TestComponentBool", Estimate: 4},
+		}, plan)
+	})
+}
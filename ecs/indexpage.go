@@ -3,21 +3,19 @@ package ecs
 import (
 	"fmt"
 	"iter"
-	"sort"
 
 	"github.com/PieterD/boevig/rang"
-	"github.com/samber/lo"
 )
 
 type indexPageG[T comparable] struct {
 	idToValue  map[EntityID]T
-	valueToIDs map[T]map[EntityID]struct{}
+	valueToIDs map[T]*rang.Bitmap[EntityID]
 }
 
 func newIndexPageG[T comparable]() *indexPageG[T] {
 	return &indexPageG[T]{
 		idToValue:  make(map[EntityID]T),
-		valueToIDs: make(map[T]map[EntityID]struct{}),
+		valueToIDs: make(map[T]*rang.Bitmap[EntityID]),
 	}
 }
 
@@ -35,14 +33,15 @@ func (page *indexPageG[T]) SetG(id EntityID, value T) {
 		if existingValue == value {
 			return
 		}
-		delete(page.idToValue, id)
-		delete(page.valueToIDs[existingValue], id)
+		page.Remove(id)
 	}
 	page.idToValue[id] = value
-	if _, ok := page.valueToIDs[value]; !ok {
-		page.valueToIDs[value] = make(map[EntityID]struct{})
+	ids, ok := page.valueToIDs[value]
+	if !ok {
+		ids = rang.NewBitmap[EntityID]()
+		page.valueToIDs[value] = ids
 	}
-	page.valueToIDs[value][id] = struct{}{}
+	ids.Add(id)
 }
 
 func (page *indexPageG[T]) Remove(id EntityID) {
@@ -50,8 +49,10 @@ func (page *indexPageG[T]) Remove(id EntityID) {
 	if !ok {
 		return
 	}
-	delete(page.valueToIDs[value], id)
-	if len(page.valueToIDs[value]) == 0 {
+	delete(page.idToValue, id)
+	ids := page.valueToIDs[value]
+	ids.Remove(id)
+	if ids.Len() == 0 {
 		delete(page.valueToIDs, value)
 	}
 }
@@ -65,29 +66,114 @@ func (page *indexPageG[T]) SeekSeq(vi any) iter.Seq[rang.Seekable[EntityID]] {
 }
 
 func (page *indexPageG[T]) SeekSeqG(value T) iter.Seq[rang.Seekable[EntityID]] {
-	return rang.NewOrdered(func(a, b EntityID) bool { return a < b }).SeekIterator(func(start *EntityID) iter.Seq[EntityID] {
-		return func(yield func(EntityID) bool) {
-			m := page.valueToIDs[value]
-			if len(m) == 0 {
+	return func(yield func(rang.Seekable[EntityID]) bool) {
+		ids, ok := page.valueToIDs[value]
+		if !ok {
+			return
+		}
+		for sid := range ids.SeekSeq() {
+			if !yield(sid) {
 				return
 			}
-			ids := lo.Keys(m)
-			sort.Slice(ids, func(i, j int) bool {
-				return ids[i] < ids[j]
-			})
-			id := ids[0]
-			if start != nil {
-				id = *start
-			}
-			n := sort.Search(len(ids), func(i int) bool { return ids[i] >= id })
-			if n == len(ids) {
+		}
+	}
+}
+
+func (page *indexPageG[T]) ReverseSeekSeqG(value T) iter.Seq[rang.Seekable[EntityID]] {
+	return func(yield func(rang.Seekable[EntityID]) bool) {
+		ids, ok := page.valueToIDs[value]
+		if !ok {
+			return
+		}
+		for sid := range ids.ReverseSeekSeq() {
+			if !yield(sid) {
 				return
 			}
-			for i := n; i < len(ids); i++ {
-				if !yield(ids[i]) {
-					return
-				}
+		}
+	}
+}
+
+func (page *indexPageG[T]) ValueG(id EntityID) (T, bool) {
+	value, ok := page.idToValue[id]
+	return value, ok
+}
+
+func (page *indexPageG[T]) AllG() iter.Seq2[EntityID, T] {
+	return func(yield func(EntityID, T) bool) {
+		for id, value := range page.idToValue {
+			if !yield(id, value) {
+				return
 			}
 		}
-	})
+	}
+}
+
+func (page *indexPageG[T]) LenG(value T) int {
+	ids, ok := page.valueToIDs[value]
+	if !ok {
+		return 0
+	}
+	return ids.Len()
+}
+
+func (page *indexPageG[T]) LenMatchG(match func(T) bool) int {
+	n := 0
+	for value, ids := range page.valueToIDs {
+		if match(value) {
+			n += ids.Len()
+		}
+	}
+	return n
+}
+
+func (page *indexPageG[T]) CountsG() map[T]int {
+	counts := make(map[T]int, len(page.valueToIDs))
+	for value, ids := range page.valueToIDs {
+		counts[value] = ids.Len()
+	}
+	return counts
+}
+
+func (page *indexPageG[T]) BitmapG(value T) *rang.Bitmap[EntityID] {
+	ids, ok := page.valueToIDs[value]
+	if !ok {
+		return rang.NewBitmap[EntityID]()
+	}
+	return ids
+}
+
+func (page *indexPageG[T]) SeekSeqMatchG(match func(T) bool) iter.Seq[rang.Seekable[EntityID]] {
+	var seqs []iter.Seq[rang.Seekable[EntityID]]
+	for value := range page.valueToIDs {
+		if match(value) {
+			seqs = append(seqs, page.SeekSeqG(value))
+		}
+	}
+	if len(seqs) == 0 {
+		return func(yield func(rang.Seekable[EntityID]) bool) {
+			return
+		}
+	}
+	if len(seqs) == 1 {
+		return seqs[0]
+	}
+	return rang.NewOrdered(EntityID.Less).Union(seqs...)
+}
+
+func (page *indexPageG[T]) ReverseSeekSeqMatchG(match func(T) bool) iter.Seq[rang.Seekable[EntityID]] {
+	var seqs []iter.Seq[rang.Seekable[EntityID]]
+	for value := range page.valueToIDs {
+		if match(value) {
+			seqs = append(seqs, page.ReverseSeekSeqG(value))
+		}
+	}
+	if len(seqs) == 0 {
+		return func(yield func(rang.Seekable[EntityID]) bool) {
+			return
+		}
+	}
+	if len(seqs) == 1 {
+		return seqs[0]
+	}
+	return rang.NewOrdered(EntityID.Less).Reverse().Union(seqs...)
 }
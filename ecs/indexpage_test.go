@@ -0,0 +1,103 @@
+package ecs
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/PieterD/boevig/rang"
+	"github.com/PieterD/boevig/rang/rangtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexPageG(t *testing.T) {
+	t.Run("search sorted", func(t *testing.T) {
+		page := newIndexPageG[string]()
+		page.SetG(5, "a")
+		page.SetG(1, "a")
+		page.SetG(3, "b")
+		page.SetG(2, "a")
+		require.Equal(t, []EntityID{1, 2, 5}, rang.ToSlice(rang.UnSeek(page.SeekSeqG("a"))))
+		require.Equal(t, []EntityID{3}, rang.ToSlice(rang.UnSeek(page.SeekSeqG("b"))))
+		require.Equal(t, []EntityID(nil), rang.ToSlice(rang.UnSeek(page.SeekSeqG("c"))))
+	})
+	t.Run("seek", func(t *testing.T) {
+		page := newIndexPageG[string]()
+		for id := EntityID(1); id <= 10; id++ {
+			page.SetG(id, "a")
+		}
+		var got []EntityID
+		for sid := range page.SeekSeqG("a") {
+			if sid.Value() == 2 {
+				sid.Seek(8)
+				continue
+			}
+			got = append(got, sid.Value())
+		}
+		require.Equal(t, []EntityID{1, 8, 9, 10}, got)
+	})
+	t.Run("move and remove", func(t *testing.T) {
+		page := newIndexPageG[string]()
+		page.SetG(1, "a")
+		page.SetG(2, "a")
+		page.SetG(1, "b")
+		require.Equal(t, []EntityID{2}, rang.ToSlice(rang.UnSeek(page.SeekSeqG("a"))))
+		require.Equal(t, []EntityID{1}, rang.ToSlice(rang.UnSeek(page.SeekSeqG("b"))))
+		page.Remove(1)
+		page.Remove(2)
+		require.Empty(t, page.idToValue)
+		require.Empty(t, page.valueToIDs)
+	})
+}
+
+func TestIndexPageG_SeekContract(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, size := range []int{0, 10, 1000, 10000} {
+		page := newIndexPageG[int]()
+		for _, id := range rangtest.RandomSet[EntityID](rnd, size, 3*size) {
+			page.SetG(id, int(id%3))
+		}
+		for value := 0; value < 3; value++ {
+			var want []EntityID
+			for id, v := range page.idToValue {
+				if v == value {
+					want = append(want, id)
+				}
+			}
+			slices.Sort(want)
+			require.NoError(t, rangtest.CheckSeekSeq(page.SeekSeqG(value), want, rnd), "size %d value %d", size, value)
+			require.NoError(t, rangtest.CheckCursor(page.BitmapG(value).Cursor, want, rnd), "size %d value %d", size, value)
+		}
+		match := func(v int) bool { return v != 1 }
+		want := rangtest.Union(rang.ToSlice(rang.UnSeek(page.SeekSeqG(0))), rang.ToSlice(rang.UnSeek(page.SeekSeqG(2))))
+		require.NoError(t, rangtest.CheckSeekSeq(page.SeekSeqMatchG(match), want, rnd), "size %d match", size)
+	}
+}
+
+func BenchmarkIndexPageG_Seek(b *testing.B) {
+	for _, size := range []int{100, 1000, 10000} {
+		b.Run(fmt.Sprintf("bucket %d", size), func(b *testing.B) {
+			page := newIndexPageG[testCoord]()
+			for id := EntityID(1); id <= EntityID(size); id++ {
+				page.SetG(id, testCoord{X: 1, Y: 1})
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				target := EntityID(i%size) + 1
+				for sid := range page.SeekSeqG(testCoord{X: 1, Y: 1}) {
+					if sid.Value() < target {
+						sid.Seek(target)
+						continue
+					}
+					break
+				}
+			}
+		})
+	}
+}
+
+type testCoord struct {
+	X int
+	Y int
+}
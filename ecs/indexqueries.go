@@ -1,6 +1,8 @@
 package ecs
 
 import (
+	"errors"
+	"fmt"
 	"iter"
 
 	"github.com/PieterD/boevig/rang"
@@ -10,8 +12,26 @@ type Indexer interface {
 	search(book *indexBook) iter.Seq[rang.Seekable[EntityID]]
 	apply(book *indexBook, id EntityID)
 	remove(book *indexBook, id EntityID)
+	check(book *indexBook, id EntityID) error
+	estimate(book *indexBook) int
+	matches(book *indexBook, id EntityID) bool
+	// reverseSearch is search in descending order.
+	reverseSearch(book *indexBook) iter.Seq[rang.Seekable[EntityID]]
 }
 
+// bitmapIndexer is implemented by indexers whose matches already are a bitmap,
+// so intersecting them natively costs no more than seeking.
+type bitmapIndexer interface {
+	bitmap(book *indexBook) *rang.Bitmap[EntityID]
+}
+
+type LookupIndexer interface {
+	Indexer
+	lookup(book *indexBook) (EntityID, bool)
+}
+
+var ErrUniqueViolation = errors.New("unique index violation")
+
 func EQ[T comparable](indexName string, value T) EqualityIndexer[T] {
 	return EqualityIndexer[T]{
 		IndexName: indexName,
@@ -29,6 +49,31 @@ func (es EqualityIndexer[T]) search(book *indexBook) iter.Seq[rang.Seekable[Enti
 	return page.SeekSeqG(es.Value)
 }
 
+func (es EqualityIndexer[T]) reverseSearch(book *indexBook) iter.Seq[rang.Seekable[EntityID]] {
+	page := getPageG(book, es.IndexName, es.Value)
+	return page.ReverseSeekSeqG(es.Value)
+}
+
+func (es EqualityIndexer[T]) estimate(book *indexBook) int {
+	page := getPageG(book, es.IndexName, es.Value)
+	return page.LenG(es.Value)
+}
+
+func (es EqualityIndexer[T]) matches(book *indexBook, id EntityID) bool {
+	page := getPageG(book, es.IndexName, es.Value)
+	value, ok := page.ValueG(id)
+	return ok && value == es.Value
+}
+
+func (es EqualityIndexer[T]) String() string {
+	return fmt.Sprintf("%s = %+v", es.IndexName, es.Value)
+}
+
+func (es EqualityIndexer[T]) bitmap(book *indexBook) *rang.Bitmap[EntityID] {
+	page := getPageG(book, es.IndexName, es.Value)
+	return page.BitmapG(es.Value)
+}
+
 func (es EqualityIndexer[T]) apply(book *indexBook, id EntityID) {
 	page := getPageG(book, es.IndexName, es.Value)
 	page.Set(id, es.Value)
@@ -38,3 +83,76 @@ func (es EqualityIndexer[T]) remove(book *indexBook, id EntityID) {
 	page := getPageG(book, es.IndexName, es.Value)
 	page.Remove(id)
 }
+
+func (es EqualityIndexer[T]) check(book *indexBook, id EntityID) error {
+	return nil
+}
+
+func Unique[T comparable](indexName string, value T) UniqueIndexer[T] {
+	return UniqueIndexer[T]{
+		IndexName: indexName,
+		Value:     value,
+	}
+}
+
+type UniqueIndexer[T comparable] struct {
+	IndexName string
+	Value     T
+}
+
+func (us UniqueIndexer[T]) search(book *indexBook) iter.Seq[rang.Seekable[EntityID]] {
+	page := getUniquePageG(book, us.IndexName, us.Value)
+	return page.SeekSeqG(us.Value)
+}
+
+func (us UniqueIndexer[T]) reverseSearch(book *indexBook) iter.Seq[rang.Seekable[EntityID]] {
+	page := getUniquePageG(book, us.IndexName, us.Value)
+	return page.ReverseSeekSeqG(us.Value)
+}
+
+func (us UniqueIndexer[T]) estimate(book *indexBook) int {
+	page := getUniquePageG(book, us.IndexName, us.Value)
+	return page.LenG(us.Value)
+}
+
+func (us UniqueIndexer[T]) matches(book *indexBook, id EntityID) bool {
+	page := getUniquePageG(book, us.IndexName, us.Value)
+	value, ok := page.ValueG(id)
+	return ok && value == us.Value
+}
+
+func (us UniqueIndexer[T]) bitmap(book *indexBook) *rang.Bitmap[EntityID] {
+	page := getUniquePageG(book, us.IndexName, us.Value)
+	id, ok := page.LookupG(us.Value)
+	if !ok {
+		return rang.NewBitmap[EntityID]()
+	}
+	return rang.NewBitmap(id)
+}
+
+func (us UniqueIndexer[T]) String() string {
+	return fmt.Sprintf("%s = %+v (unique)", us.IndexName, us.Value)
+}
+
+func (us UniqueIndexer[T]) apply(book *indexBook, id EntityID) {
+	page := getUniquePageG(book, us.IndexName, us.Value)
+	page.Set(id, us.Value)
+}
+
+func (us UniqueIndexer[T]) remove(book *indexBook, id EntityID) {
+	page := getUniquePageG(book, us.IndexName, us.Value)
+	page.Remove(id)
+}
+
+func (us UniqueIndexer[T]) check(book *indexBook, id EntityID) error {
+	page := getUniquePageG(book, us.IndexName, us.Value)
+	if err := page.CheckG(id, us.Value); err != nil {
+		return fmt.Errorf("checking unique index %s: %w", us.IndexName, err)
+	}
+	return nil
+}
+
+func (us UniqueIndexer[T]) lookup(book *indexBook) (EntityID, bool) {
+	page := getUniquePageG(book, us.IndexName, us.Value)
+	return page.LookupG(us.Value)
+}
@@ -0,0 +1,130 @@
+package ecs
+
+import (
+	"fmt"
+	"iter"
+	"reflect"
+
+	"github.com/PieterD/boevig/rang"
+)
+
+type uniquePageG[T comparable] struct {
+	idToValue map[EntityID]T
+	valueToID map[T]EntityID
+}
+
+func newUniquePageG[T comparable]() *uniquePageG[T] {
+	return &uniquePageG[T]{
+		idToValue: make(map[EntityID]T),
+		valueToID: make(map[T]EntityID),
+	}
+}
+
+func (page *uniquePageG[T]) Set(id EntityID, vi any) {
+	v, ok := vi.(T)
+	if !ok {
+		panic(fmt.Errorf("adding to unique index %v %+v: invalid type, got %T, want %T", id, vi, vi, v))
+	}
+	if err := page.SetG(id, v); err != nil {
+		panic(err)
+	}
+}
+
+func (page *uniquePageG[T]) CheckG(id EntityID, value T) error {
+	existingID, ok := page.valueToID[value]
+	if ok && existingID != id {
+		return fmt.Errorf("adding %v to unique index: value %+v already taken by %v: %w", id, value, existingID, ErrUniqueViolation)
+	}
+	return nil
+}
+
+func (page *uniquePageG[T]) SetG(id EntityID, value T) error {
+	if err := page.CheckG(id, value); err != nil {
+		return err
+	}
+	page.Remove(id)
+	page.idToValue[id] = value
+	page.valueToID[value] = id
+	return nil
+}
+
+func (page *uniquePageG[T]) Remove(id EntityID) {
+	value, ok := page.idToValue[id]
+	if !ok {
+		return
+	}
+	delete(page.idToValue, id)
+	delete(page.valueToID, value)
+}
+
+func (page *uniquePageG[T]) LookupG(value T) (EntityID, bool) {
+	id, ok := page.valueToID[value]
+	return id, ok
+}
+
+func (page *uniquePageG[T]) ValueG(id EntityID) (T, bool) {
+	value, ok := page.idToValue[id]
+	return value, ok
+}
+
+func (page *uniquePageG[T]) LenG(value T) int {
+	if _, ok := page.valueToID[value]; ok {
+		return 1
+	}
+	return 0
+}
+
+func (page *uniquePageG[T]) SeekSeq(vi any) iter.Seq[rang.Seekable[EntityID]] {
+	v, ok := vi.(T)
+	if !ok {
+		panic(fmt.Errorf("seekseq on unique index %+v: invalid type, got %T, want %T", vi, vi, v))
+	}
+	return page.SeekSeqG(v)
+}
+
+func (page *uniquePageG[T]) SeekSeqG(value T) iter.Seq[rang.Seekable[EntityID]] {
+	return rang.NewOrdered(EntityID.Less).SeekIterator(func(start *EntityID) iter.Seq[EntityID] {
+		return func(yield func(EntityID) bool) {
+			id, ok := page.valueToID[value]
+			if !ok {
+				return
+			}
+			if start != nil && id < *start {
+				return
+			}
+			yield(id)
+		}
+	})
+}
+
+func (page *uniquePageG[T]) ReverseSeekSeqG(value T) iter.Seq[rang.Seekable[EntityID]] {
+	return rang.NewOrdered(EntityID.Less).Reverse().SeekIterator(func(start *EntityID) iter.Seq[EntityID] {
+		return func(yield func(EntityID) bool) {
+			id, ok := page.valueToID[value]
+			if !ok {
+				return
+			}
+			if start != nil && id > *start {
+				return
+			}
+			yield(id)
+		}
+	})
+}
+
+func getUniquePageG[T comparable](book *indexBook, indexName string, value T) *uniquePageG[T] {
+	tup := indexTuple{
+		Name: indexName,
+		Type: reflect.TypeOf(value),
+	}
+	page, ok := book.pages[tup]
+	if !ok {
+		page = newUniquePageG[T]()
+		book.pages[tup] = page
+	}
+	uniquePage, ok := page.(*uniquePageG[T])
+	if !ok {
+		panic(fmt.Errorf("fetching unique index %s: index exists with different kind %T", indexName, page))
+	}
+	return uniquePage
+}
@@ -0,0 +1,142 @@
+package ecs
+
+import (
+	"fmt"
+	"iter"
+
+	"github.com/PieterD/boevig/rang"
+)
+
+// Relation is a set of (from, to) entity pairs that can be joined on.
+type Relation interface {
+	pairs(db *DB) iter.Seq2[EntityID, EntityID]
+}
+
+// IndexRelation relates every entity to the EntityID it reports to an equality index,
+// such as a Hostile component indexing EQ("Hostile.Target", c.Target).
+// Only EQ indexes on an EntityID can be related, joining on any other index panics.
+func IndexRelation(indexName string) Relation {
+	return indexRelation{IndexName: indexName}
+}
+
+type indexRelation struct {
+	IndexName string
+}
+
+func (r indexRelation) pairs(db *DB) iter.Seq2[EntityID, EntityID] {
+	for tup, page := range db.indices.pages {
+		if tup.Name != r.IndexName {
+			continue
+		}
+		if _, ok := page.(*indexPageG[EntityID]); !ok {
+			panic(fmt.Errorf("joining index relation %s: index is %T, want an EQ index on EntityID", r.IndexName, page))
+		}
+	}
+	page := getPageG(db.indices, r.IndexName, EntityID(0))
+	return page.AllG()
+}
+
+// PairRelation relates arbitrary pairs, such as adjacency computed from locations.
+func PairRelation(pairs iter.Seq2[EntityID, EntityID]) Relation {
+	return pairRelation{seq: pairs}
+}
+
+type pairRelation struct {
+	seq iter.Seq2[EntityID, EntityID]
+}
+
+func (r pairRelation) pairs(db *DB) iter.Seq2[EntityID, EntityID] {
+	return r.seq
+}
+
+// JoinBuilder joins relations and searches on a number of entity variables,
+// using a worst-case optimal leapfrog triejoin.
+type JoinBuilder struct {
+	db      *DB
+	numVars int
+	atoms   []joinAtom
+}
+
+type joinAtom struct {
+	tuples func() [][]EntityID
+	vars   []int
+}
+
+func (db *DB) Join(numVars int) *JoinBuilder {
+	return &JoinBuilder{db: db, numVars: numVars}
+}
+
+// Relation requires the entity in variable from to be related to the one in variable to.
+func (j *JoinBuilder) Relation(rel Relation, from, to int) *JoinBuilder {
+	if from == to {
+		j.atoms = append(j.atoms, joinAtom{
+			vars: []int{from},
+			tuples: func() [][]EntityID {
+				var tuples [][]EntityID
+				for a, b := range rel.pairs(j.db) {
+					if a == b {
+						tuples = append(tuples, []EntityID{a})
+					}
+				}
+				return tuples
+			},
+		})
+		return j
+	}
+	swap := from > to
+	vars := []int{from, to}
+	if swap {
+		vars = []int{to, from}
+	}
+	j.atoms = append(j.atoms, joinAtom{
+		vars: vars,
+		tuples: func() [][]EntityID {
+			var tuples [][]EntityID
+			for a, b := range rel.pairs(j.db) {
+				if swap {
+					a, b = b, a
+				}
+				tuples = append(tuples, []EntityID{a, b})
+			}
+			return tuples
+		},
+	})
+	return j
+}
+
+// Search requires the entity in variable v to match the search.
+func (j *JoinBuilder) Search(v int, b *SearchBuilder) *JoinBuilder {
+	j.atoms = append(j.atoms, joinAtom{
+		vars: []int{v},
+		tuples: func() [][]EntityID {
+			var tuples [][]EntityID
+			for id, err := range b.ids() {
+				if err != nil {
+					panic(fmt.Errorf("joining search on variable %d: %w", v, err))
+				}
+				tuples = append(tuples, []EntityID{id})
+			}
+			return tuples
+		},
+	})
+	return j
+}
+
+// Done yields the entities bound to each variable, for every combination that satisfies the join.
+// The yielded slice is reused between iterations.
+func (j *JoinBuilder) Done() iter.Seq[[]EntityID] {
+	return func(yield func([]EntityID) bool) {
+		var atoms []rang.TrieAtom[EntityID]
+		for _, atom := range j.atoms {
+			atoms = append(atoms, rang.TrieAtom[EntityID]{
+				Trie: rang.NewSliceTrie(EntityID.Less, atom.tuples()),
+				Vars: atom.vars,
+			})
+		}
+		for binding := range rang.NewOrdered(EntityID.Less).TrieJoin(j.numVars, atoms...) {
+			if !yield(binding) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type TestComponentHostile struct {
+	ComponentHeader[TestComponentHostile, *TestComponentHostile]
+	Target EntityID
+}
+
+func (c TestComponentHostile) Index() []Indexer {
+	return []Indexer{
+		EQ("Hostile.Target", c.Target),
+	}
+}
+
+func TestJoinBuilder(t *testing.T) {
+	db := New()
+	player := db.NewEntity(TestComponentString{String: "player"})
+	ally := db.NewEntity(TestComponentString{String: "ally"})
+	rat := db.NewEntity(TestComponentHostile{Target: player})
+	bat := db.NewEntity(TestComponentHostile{Target: player})
+	ghost := db.NewEntity(TestComponentHostile{Target: ally})
+	adjacent := [][2]EntityID{
+		{player, rat},
+		{player, ghost},
+		{ally, ghost},
+		{ally, bat},
+	}
+	adjacency := PairRelation(func(yield func(EntityID, EntityID) bool) {
+		for _, pair := range adjacent {
+			if !yield(pair[0], pair[1]) || !yield(pair[1], pair[0]) {
+				return
+			}
+		}
+	})
+	collect := func(j *JoinBuilder) [][]EntityID {
+		var got [][]EntityID
+		for binding := range j.Done() {
+			got = append(got, append([]EntityID(nil), binding...))
+		}
+		return got
+	}
+	t.Run("adjacent and hostile", func(t *testing.T) {
+		// A adjacent to B, where B is hostile to A.
+		got := collect(db.Join(2).
+			Relation(adjacency, 0, 1).
+			Relation(IndexRelation("Hostile.Target"), 1, 0))
+		require.Equal(t, [][]EntityID{{player, rat}, {ally, ghost}}, got)
+	})
+	t.Run("restricted by search", func(t *testing.T) {
+		var str TestComponentString
+		got := collect(db.Join(2).
+			Relation(adjacency, 0, 1).
+			Relation(IndexRelation("Hostile.Target"), 1, 0).
+			Search(0, db.Search().Components(&str).After(player)))
+		require.Equal(t, [][]EntityID{{ally, ghost}}, got)
+		got = collect(db.Join(2).
+			Relation(adjacency, 0, 1).
+			Relation(IndexRelation("Hostile.Target"), 1, 0).
+			Search(1, db.Search().Index(EQ("Hostile.Target", ally))))
+		require.Equal(t, [][]EntityID{{ally, ghost}}, got)
+		got = collect(db.Join(2).
+			Relation(adjacency, 0, 1).
+			Search(1, db.Search().Index(EQ("Hostile.Target", player))))
+		require.Equal(t, [][]EntityID{{player, rat}, {ally, bat}}, got)
+	})
+	t.Run("index on other values", func(t *testing.T) {
+		db.NewEntity(TestComponentIndex{String: "s", Num: 1})
+		require.PanicsWithError(t, "joining index relation test_num: index is *ecs.indexPageG[int], want an EQ index on EntityID", func() {
+			collect(db.Join(2).Relation(IndexRelation("test_num"), 0, 1))
+		})
+	})
+}
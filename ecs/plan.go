@@ -0,0 +1,280 @@
+package ecs
+
+import (
+	"fmt"
+	"iter"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/PieterD/boevig/rang"
+)
+
+// UnknownEstimate is the PlanStep estimate of a search source that cannot tell its size.
+const UnknownEstimate = -1
+
+type searchSource interface {
+	estimate() int
+	seekSeq() iter.Seq2[rang.Seekable[EntityID], error]
+	reverseSeekSeq() iter.Seq2[rang.Seekable[EntityID], error]
+	// cursor returns false if the source has no native cursor.
+	cursor() (rang.Cursor[EntityID], bool)
+	// bitmap returns false if the source is not a bitmap. The result must not be modified.
+	bitmap() (*rang.Bitmap[EntityID], bool)
+	contains(id EntityID) (bool, error)
+	exact() bool
+	String() string
+}
+
+type componentSource struct {
+	page         cStorePage
+	componentPtr Component
+}
+
+func (s componentSource) estimate() int {
+	return s.page.Len()
+}
+
+func (s componentSource) seekSeq() iter.Seq2[rang.Seekable[EntityID], error] {
+	return rang.Fallible(s.page.SeekSeq())
+}
+
+func (s componentSource) reverseSeekSeq() iter.Seq2[rang.Seekable[EntityID], error] {
+	return rang.Fallible(s.page.ReverseSeekSeq())
+}
+
+func (s componentSource) cursor() (rang.Cursor[EntityID], bool) {
+	return s.page.Cursor(), true
+}
+
+func (s componentSource) bitmap() (*rang.Bitmap[EntityID], bool) {
+	return s.page.Bitmap(), true
+}
+
+func (s componentSource) contains(id EntityID) (bool, error) {
+	return s.page.Has(id), nil
+}
+
+func (s componentSource) exact() bool {
+	return true
+}
+
+func (s componentSource) String() string {
+	return fmt.Sprintf("component %v", s.componentPtr.typ())
+}
+
+type indexSource struct {
+	book     *indexBook
+	indexers []Indexer
+}
+
+func (s indexSource) estimate() int {
+	smallest := UnknownEstimate
+	for _, indexer := range s.indexers {
+		n := indexer.estimate(s.book)
+		if smallest == UnknownEstimate || n < smallest {
+			smallest = n
+		}
+	}
+	return smallest
+}
+
+func (s indexSource) seekSeq() iter.Seq2[rang.Seekable[EntityID], error] {
+	return rang.Fallible(s.book.Search(s.indexers...))
+}
+
+func (s indexSource) reverseSeekSeq() iter.Seq2[rang.Seekable[EntityID], error] {
+	return rang.Fallible(s.book.ReverseSearch(s.indexers...))
+}
+
+func (s indexSource) cursor() (rang.Cursor[EntityID], bool) {
+	ids, ok := s.bitmap()
+	if !ok {
+		return nil, false
+	}
+	return ids.Cursor(), true
+}
+
+func (s indexSource) bitmap() (*rang.Bitmap[EntityID], bool) {
+	return s.book.Bitmap(s.indexers...)
+}
+
+func (s indexSource) contains(id EntityID) (bool, error) {
+	for _, indexer := range s.indexers {
+		if !indexer.matches(s.book, id) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (s indexSource) exact() bool {
+	return len(s.indexers) == 1
+}
+
+func (s indexSource) String() string {
+	var descs []string
+	for _, indexer := range s.indexers {
+		descs = append(descs, fmt.Sprint(indexer))
+	}
+	return fmt.Sprintf("index %s", strings.Join(descs, ", "))
+}
+
+type seqSource struct {
+	seq iter.Seq2[rang.Seekable[EntityID], error]
+	// ids is set by collect, contains probes it instead of reading seq.
+	ids *rang.Bitmap[EntityID]
+}
+
+func (s seqSource) estimate() int {
+	return UnknownEstimate
+}
+
+func (s seqSource) seekSeq() iter.Seq2[rang.Seekable[EntityID], error] {
+	return s.seq
+}
+
+// reverseSeekSeq has to collect the whole sequence, since it can only be read forwards.
+func (s seqSource) reverseSeekSeq() iter.Seq2[rang.Seekable[EntityID], error] {
+	return func(yield func(rang.Seekable[EntityID], error) bool) {
+		collected, err := s.collect()
+		if err != nil {
+			yield(rang.Seekable[EntityID]{}, err)
+			return
+		}
+		for sid := range collected.ids.ReverseSeekSeq() {
+			if !yield(sid, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (s seqSource) cursor() (rang.Cursor[EntityID], bool) {
+	return nil, false
+}
+
+func (s seqSource) bitmap() (*rang.Bitmap[EntityID], bool) {
+	return nil, false
+}
+
+// collect reads the sequence into a bitmap, for searches that probe it many times.
+func (s seqSource) collect() (seqSource, error) {
+	ids := rang.NewBitmap[EntityID]()
+	for sid, err := range s.seq {
+		if err != nil {
+			return s, err
+		}
+		ids.Add(sid.Value())
+	}
+	s.ids = ids
+	return s, nil
+}
+
+func (s seqSource) contains(id EntityID) (bool, error) {
+	if s.ids != nil {
+		return s.ids.Contains(id), nil
+	}
+	for sid, err := range s.seq {
+		if err != nil {
+			return false, err
+		}
+		if sid.Value() < id {
+			sid.Seek(id)
+			continue
+		}
+		return sid.Value() == id, nil
+	}
+	return false, nil
+}
+
+func (s seqSource) exact() bool {
+	return false
+}
+
+func (s seqSource) String() string {
+	return "sequence"
+}
+
+type cachedSource struct {
+	query *CachedQuery
+}
+
+func (s cachedSource) estimate() int {
+	return s.query.Len()
+}
+
+func (s cachedSource) seekSeq() iter.Seq2[rang.Seekable[EntityID], error] {
+	return rang.Fallible(s.query.SeekSeq())
+}
+
+func (s cachedSource) reverseSeekSeq() iter.Seq2[rang.Seekable[EntityID], error] {
+	return rang.Fallible(s.query.ids.ReverseSeekSeq())
+}
+
+func (s cachedSource) cursor() (rang.Cursor[EntityID], bool) {
+	return s.query.ids.Cursor(), true
+}
+
+func (s cachedSource) bitmap() (*rang.Bitmap[EntityID], bool) {
+	return s.query.ids, true
+}
+
+func (s cachedSource) contains(id EntityID) (bool, error) {
+	return s.query.matches(id), nil
+}
+
+func (s cachedSource) exact() bool {
+	return true
+}
+
+func (s cachedSource) String() string {
+	return "cached query"
+}
+
+// Plan is the order in which a search intersects its sources.
+// The first step drives the intersection, the others are sought to its values.
+type Plan []PlanStep
+
+type PlanStep struct {
+	Source string
+	// Estimate is the number of entities the source yields, or UnknownEstimate.
+	Estimate int
+}
+
+func (p Plan) String() string {
+	var lines []string
+	for i, step := range p {
+		estimate := "?"
+		if step.Estimate != UnknownEstimate {
+			estimate = fmt.Sprint(step.Estimate)
+		}
+		lines = append(lines, fmt.Sprintf("%d. %s (~%s)", i+1, step.Source, estimate))
+	}
+	return strings.Join(lines, "\n")
+}
+
+type plannedSource struct {
+	source   searchSource
+	estimate int
+}
+
+func planSources(sources []searchSource) []plannedSource {
+	planned := make([]plannedSource, 0, len(sources))
+	for _, source := range sources {
+		planned = append(planned, plannedSource{
+			source:   source,
+			estimate: source.estimate(),
+		})
+	}
+	sortKey := func(estimate int) int {
+		if estimate == UnknownEstimate {
+			return math.MaxInt
+		}
+		return estimate
+	}
+	sort.SliceStable(planned, func(i, j int) bool {
+		return sortKey(planned[i].estimate) < sortKey(planned[j].estimate)
+	})
+	return planned
+}
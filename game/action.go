@@ -0,0 +1,321 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PieterD/boevig/ecs"
+	"github.com/PieterD/boevig/game/rng"
+	"github.com/PieterD/boevig/rang"
+)
+
+// World is what actions act on.
+type World struct {
+	DB  *ecs.DB
+	Map *Map
+	RNG *rng.RNG
+}
+
+func NewWorld(db *ecs.DB, m *Map, r *rng.RNG) *World {
+	if db == nil || m == nil || r == nil {
+		panic(fmt.Errorf("creating world: db, map and rng are required"))
+	}
+	return &World{DB: db, Map: m, RNG: r}
+}
+
+// At returns the entities on loc.
+func (w *World) At(loc GridLocation) []ecs.EntityID {
+	return rang.ToSlice(w.DB.Search().Index(ecs.EQ(LocationIndex, loc)).Done())
+}
+
+// Blocking marks entities that others cannot move through, like monsters.
+type Blocking struct {
+	ecs.ComponentHeader[Blocking, *Blocking]
+}
+
+// Item marks entities that can be picked up.
+type Item struct {
+	ecs.ComponentHeader[Item, *Item]
+	Name string
+}
+
+// CarriedIndex finds the items held by an entity.
+const CarriedIndex = "game.Carried"
+
+// Carried is set on items instead of a GridLocation while someone holds them.
+type Carried struct {
+	ecs.ComponentHeader[Carried, *Carried]
+	By ecs.EntityID
+}
+
+func (c Carried) Index() []ecs.Indexer {
+	return []ecs.Indexer{
+		ecs.EQ(CarriedIndex, c.By),
+	}
+}
+
+// Energy costs of the built in actions.
+const (
+	MoveCost   = 100
+	AttackCost = 100
+	PickUpCost = 50
+	WaitCost   = 100
+)
+
+var (
+	ErrNoLocation = errors.New("actor has no location")
+	ErrOutOfReach = errors.New("out of reach")
+	ErrNoItem     = errors.New("no item")
+)
+
+// Action is something an entity does on its turn.
+type Action interface {
+	// Cost is the energy the action takes.
+	Cost() int
+	// Validate checks the action against the world. Instead of failing,
+	// it may return an alternate action to perform instead, like an attack when moving into a monster.
+	Validate(w *World, actor ecs.EntityID) (alternate Action, err error)
+	// Apply performs a validated action.
+	Apply(w *World, actor ecs.EntityID) []Event
+}
+
+// Event is a result of an action, for the message log and the screen.
+type Event interface {
+	String() string
+}
+
+type Result struct {
+	// Action is the action that was performed, which may be an alternate of the one requested.
+	Action Action
+	Events []Event
+}
+
+// maxAlternates stops actions that keep offering alternates.
+const maxAlternates = 8
+
+// Perform validates and applies an action, following its alternates.
+// Nothing changes if it returns an error, and the actor's turn is not over.
+func Perform(w *World, actor ecs.EntityID, action Action) (Result, error) {
+	for i := 0; i < maxAlternates; i++ {
+		alternate, err := action.Validate(w, actor)
+		if err != nil {
+			return Result{}, fmt.Errorf("%v by %v: %w", action, actor, err)
+		}
+		if alternate != nil {
+			action = alternate
+			continue
+		}
+		return Result{
+			Action: action,
+			Events: action.Apply(w, actor),
+		}, nil
+	}
+	return Result{}, fmt.Errorf("%v by %v: too many alternate actions", action, actor)
+}
+
+type Move struct {
+	Dir Direction
+}
+
+func (a Move) Cost() int {
+	return MoveCost
+}
+
+func (a Move) Validate(w *World, actor ecs.EntityID) (Action, error) {
+	if !a.Dir.Valid() {
+		return nil, fmt.Errorf("invalid direction %v", a.Dir)
+	}
+	var loc GridLocation
+	if !w.DB.Get(actor, &loc) {
+		return nil, ErrNoLocation
+	}
+	to := loc.Step(a.Dir)
+	if err := w.Map.Check(to); err != nil {
+		return nil, err
+	}
+	var blocking Blocking
+	for _, id := range w.At(to) {
+		if w.DB.Get(id, &blocking) {
+			return Attack{Target: id}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (a Move) Apply(w *World, actor ecs.EntityID) []Event {
+	var from GridLocation
+	w.DB.Get(actor, &from)
+	to := from.Step(a.Dir)
+	w.DB.Set(actor, to)
+	return []Event{Moved{Actor: actor, From: from, To: to}}
+}
+
+func (a Move) String() string {
+	return fmt.Sprintf("move %v", a.Dir)
+}
+
+type Attack struct {
+	Target ecs.EntityID
+}
+
+func (a Attack) Cost() int {
+	return AttackCost
+}
+
+// Validate allows attacks on adjacent entities.
+func (a Attack) Validate(w *World, actor ecs.EntityID) (Action, error) {
+	var from, to GridLocation
+	if !w.DB.Get(actor, &from) {
+		return nil, ErrNoLocation
+	}
+	if !w.DB.Get(a.Target, &to) || max(abs(to.X-from.X), abs(to.Y-from.Y)) != 1 {
+		return nil, fmt.Errorf("target %v: %w", a.Target, ErrOutOfReach)
+	}
+	return nil, nil
+}
+
+// Apply resolves the attack, if the actor has an Offense and the target has Health.
+func (a Attack) Apply(w *World, actor ecs.EntityID) []Event {
+	return append([]Event{Attacked{Actor: actor, Target: a.Target}}, Resolve(w, actor, a.Target)...)
+}
+
+func (a Attack) String() string {
+	return fmt.Sprintf("attack %v", a.Target)
+}
+
+// PickUp picks up Item from the actor's location, or the first item there if Item is zero.
+type PickUp struct {
+	Item ecs.EntityID
+}
+
+func (a PickUp) Cost() int {
+	return PickUpCost
+}
+
+func (a PickUp) Validate(w *World, actor ecs.EntityID) (Action, error) {
+	var loc GridLocation
+	if !w.DB.Get(actor, &loc) {
+		return nil, ErrNoLocation
+	}
+	var item Item
+	if a.Item == 0 {
+		for _, id := range w.At(loc) {
+			if w.DB.Get(id, &item) {
+				return PickUp{Item: id}, nil
+			}
+		}
+		return nil, ErrNoItem
+	}
+	var itemLoc GridLocation
+	if !w.DB.Get(a.Item, &item) {
+		return nil, fmt.Errorf("%v: %w", a.Item, ErrNoItem)
+	}
+	if !w.DB.Get(a.Item, &itemLoc) || itemLoc != loc {
+		return nil, fmt.Errorf("item %v: %w", a.Item, ErrOutOfReach)
+	}
+	return nil, nil
+}
+
+func (a PickUp) Apply(w *World, actor ecs.EntityID) []Event {
+	w.DB.Unset(a.Item, GridLocation{})
+	w.DB.Set(a.Item, Carried{By: actor})
+	return []Event{PickedUp{Actor: actor, Item: a.Item}}
+}
+
+func (a PickUp) String() string {
+	return fmt.Sprintf("pick up %v", a.Item)
+}
+
+type Wait struct{}
+
+func (a Wait) Cost() int {
+	return WaitCost
+}
+
+func (a Wait) Validate(w *World, actor ecs.EntityID) (Action, error) {
+	return nil, nil
+}
+
+func (a Wait) Apply(w *World, actor ecs.EntityID) []Event {
+	return []Event{Waited{Actor: actor}}
+}
+
+func (a Wait) String() string {
+	return "wait"
+}
+
+type Moved struct {
+	Actor    ecs.EntityID
+	From, To GridLocation
+}
+
+func (e Moved) String() string {
+	return fmt.Sprintf("%v moved from %d,%d to %d,%d", e.Actor, e.From.X, e.From.Y, e.To.X, e.To.Y)
+}
+
+type Attacked struct {
+	Actor, Target ecs.EntityID
+}
+
+func (e Attacked) String() string {
+	return fmt.Sprintf("%v attacked %v", e.Actor, e.Target)
+}
+
+type PickedUp struct {
+	Actor, Item ecs.EntityID
+}
+
+func (e PickedUp) String() string {
+	return fmt.Sprintf("%v picked up %v", e.Actor, e.Item)
+}
+
+type Waited struct {
+	Actor ecs.EntityID
+}
+
+func (e Waited) String() string {
+	return fmt.Sprintf("%v waited", e.Actor)
+}
+
+// Registry turns commands, like those bound to keys, into actions.
+type Registry struct {
+	commands map[string]func(arg string) (Action, error)
+}
+
+// NewRegistry returns a registry with the commands "move <direction>", "wait" and "pickup".
+func NewRegistry() *Registry {
+	r := &Registry{commands: make(map[string]func(arg string) (Action, error))}
+	r.Register("move", func(arg string) (Action, error) {
+		dir, err := ParseDirection(arg)
+		if err != nil {
+			return nil, err
+		}
+		return Move{Dir: dir}, nil
+	})
+	r.Register("wait", func(string) (Action, error) {
+		return Wait{}, nil
+	})
+	r.Register("pickup", func(string) (Action, error) {
+		return PickUp{}, nil
+	})
+	return r
+}
+
+func (r *Registry) Register(name string, command func(arg string) (Action, error)) {
+	r.commands[name] = command
+}
+
+// Parse splits a command into its name and argument, and builds its action.
+func (r *Registry) Parse(command string) (Action, error) {
+	name, arg, _ := strings.Cut(strings.TrimSpace(command), " ")
+	build, ok := r.commands[name]
+	if !ok {
+		return nil, fmt.Errorf("parsing command %q: unknown command %q", command, name)
+	}
+	action, err := build(strings.TrimSpace(arg))
+	if err != nil {
+		return nil, fmt.Errorf("parsing command %q: %w", command, err)
+	}
+	return action, nil
+}
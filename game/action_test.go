@@ -0,0 +1,138 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/PieterD/boevig/ecs"
+	"github.com/PieterD/boevig/game/rng"
+	"github.com/stretchr/testify/require"
+)
+
+func testWorld(t *testing.T) *World {
+	return NewWorld(ecs.New(), testMap(t,
+		"#####",
+		"#...#",
+		"#.#.#",
+		"#####",
+	), rng.New(1))
+}
+
+func TestPerform(t *testing.T) {
+	t.Run("move", func(t *testing.T) {
+		w := testWorld(t)
+		hero := w.DB.NewEntity(GridLocation{X: 1, Y: 1})
+		result, err := Perform(w, hero, Move{Dir: E})
+		require.NoError(t, err)
+		require.Equal(t, MoveCost, result.Action.Cost())
+		require.Equal(t, []Event{Moved{Actor: hero, From: GridLocation{X: 1, Y: 1}, To: GridLocation{X: 2, Y: 1}}}, result.Events)
+		require.Equal(t, []ecs.EntityID{hero}, w.At(GridLocation{X: 2, Y: 1}))
+		require.Empty(t, w.At(GridLocation{X: 1, Y: 1}))
+		_, err = Perform(w, hero, Move{Dir: S})
+		require.ErrorIs(t, err, ErrImpassable)
+		_, err = Perform(w, hero, Move{Dir: N | S})
+		require.Error(t, err)
+		_, err = Perform(w, w.DB.NewEntity(), Move{Dir: S})
+		require.ErrorIs(t, err, ErrNoLocation)
+	})
+	t.Run("bump to attack", func(t *testing.T) {
+		w := testWorld(t)
+		hero := w.DB.NewEntity(GridLocation{X: 1, Y: 1})
+		monster := w.DB.NewEntity(GridLocation{X: 2, Y: 1}, Blocking{})
+		result, err := Perform(w, hero, Move{Dir: E})
+		require.NoError(t, err)
+		require.Equal(t, Attack{Target: monster}, result.Action)
+		require.Equal(t, []Event{Attacked{Actor: hero, Target: monster}}, result.Events)
+		var loc GridLocation
+		require.True(t, w.DB.Get(hero, &loc))
+		require.Equal(t, GridLocation{X: 1, Y: 1}, loc)
+	})
+	t.Run("walk over items", func(t *testing.T) {
+		w := testWorld(t)
+		hero := w.DB.NewEntity(GridLocation{X: 1, Y: 1})
+		w.DB.NewEntity(GridLocation{X: 2, Y: 1}, Item{Name: "coin"})
+		result, err := Perform(w, hero, Move{Dir: E})
+		require.NoError(t, err)
+		require.Equal(t, Move{Dir: E}, result.Action)
+	})
+	t.Run("attack reach", func(t *testing.T) {
+		w := testWorld(t)
+		hero := w.DB.NewEntity(GridLocation{X: 1, Y: 1})
+		far := w.DB.NewEntity(GridLocation{X: 3, Y: 1})
+		near := w.DB.NewEntity(GridLocation{X: 2, Y: 2})
+		_, err := Perform(w, hero, Attack{Target: far})
+		require.ErrorIs(t, err, ErrOutOfReach)
+		_, err = Perform(w, hero, Attack{Target: near})
+		require.NoError(t, err)
+	})
+	t.Run("pick up", func(t *testing.T) {
+		w := testWorld(t)
+		hero := w.DB.NewEntity(GridLocation{X: 1, Y: 1})
+		_, err := Perform(w, hero, PickUp{})
+		require.ErrorIs(t, err, ErrNoItem)
+		coin := w.DB.NewEntity(GridLocation{X: 1, Y: 1}, Item{Name: "coin"})
+		elsewhere := w.DB.NewEntity(GridLocation{X: 3, Y: 1}, Item{Name: "gem"})
+		_, err = Perform(w, hero, PickUp{Item: elsewhere})
+		require.ErrorIs(t, err, ErrOutOfReach)
+		result, err := Perform(w, hero, PickUp{})
+		require.NoError(t, err)
+		require.Equal(t, PickUp{Item: coin}, result.Action)
+		require.Equal(t, PickUpCost, result.Action.Cost())
+		require.Equal(t, []Event{PickedUp{Actor: hero, Item: coin}}, result.Events)
+		var carried Carried
+		require.True(t, w.DB.Get(coin, &carried))
+		require.Equal(t, hero, carried.By)
+		require.False(t, w.DB.Get(coin, &GridLocation{}))
+		require.Equal(t, []ecs.EntityID{hero}, w.At(GridLocation{X: 1, Y: 1}))
+	})
+	t.Run("wait", func(t *testing.T) {
+		w := testWorld(t)
+		result, err := Perform(w, 1, Wait{})
+		require.NoError(t, err)
+		require.Equal(t, "1 waited", result.Events[0].String())
+	})
+	t.Run("endless alternates", func(t *testing.T) {
+		_, err := Perform(testWorld(t), 1, loop{})
+		require.Error(t, err)
+	})
+}
+
+type loop struct {
+	Wait
+}
+
+func (a loop) Validate(w *World, actor ecs.EntityID) (Action, error) {
+	return a, nil
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	action, err := r.Parse("move ne")
+	require.NoError(t, err)
+	require.Equal(t, Move{Dir: NE}, action)
+	action, err = r.Parse(" wait ")
+	require.NoError(t, err)
+	require.Equal(t, Wait{}, action)
+	_, err = r.Parse("move up")
+	require.Error(t, err)
+	_, err = r.Parse("fly")
+	require.Error(t, err)
+	r.Register("rest", func(string) (Action, error) { return Wait{}, nil })
+	action, err = r.Parse("rest")
+	require.NoError(t, err)
+	require.Equal(t, Wait{}, action)
+}
+
+func TestScheduler_Perform(t *testing.T) {
+	w := testWorld(t)
+	player := w.DB.NewEntity(Player{}, Speed{Value: 10}, GridLocation{X: 1, Y: 1})
+	monster := w.DB.NewEntity(Speed{Value: 20}, GridLocation{X: 3, Y: 2}, Blocking{})
+	s := NewScheduler(w.DB)
+	id, ok := s.Run(func(id ecs.EntityID) int {
+		result, err := Perform(w, id, Move{Dir: N})
+		require.NoError(t, err)
+		return result.Action.Cost()
+	})
+	require.True(t, ok)
+	require.Equal(t, player, id)
+	require.Equal(t, []ecs.EntityID{monster}, w.At(GridLocation{X: 3, Y: 1}))
+}
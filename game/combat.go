@@ -0,0 +1,243 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/PieterD/boevig/ecs"
+	"github.com/PieterD/boevig/game/rng"
+	"github.com/PieterD/boevig/rang"
+)
+
+type DamageType int
+
+const (
+	Physical DamageType = iota
+	Fire
+	Cold
+	Poison
+)
+
+var damageTypeNames = map[DamageType]string{
+	Physical: "physical",
+	Fire:     "fire",
+	Cold:     "cold",
+	Poison:   "poison",
+}
+
+func (t DamageType) String() string {
+	if name, ok := damageTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("DamageType(%d)", int(t))
+}
+
+type Health struct {
+	ecs.ComponentHeader[Health, *Health]
+	Current int
+	Max     int
+}
+
+// Offense is what an entity attacks with. It is not called Attack, since that is the action.
+type Offense struct {
+	ecs.ComponentHeader[Offense, *Offense]
+	// Accuracy is the chance to hit in percent, before the target's Evasion.
+	Accuracy int
+	Damage   rng.Dice
+	Type     DamageType
+	// CritChance is the chance in percent of a hit doing CritMultiplier times the damage.
+	CritChance     int
+	CritMultiplier int
+}
+
+type Defense struct {
+	ecs.ComponentHeader[Defense, *Defense]
+	// Evasion is subtracted from the attacker's Accuracy.
+	Evasion int
+	// Armor is subtracted from physical damage.
+	Armor int
+}
+
+// Resistances reduce damage by a percentage per type. Negative values are weaknesses.
+type Resistances struct {
+	ecs.ComponentHeader[Resistances, *Resistances]
+	Percent map[DamageType]int
+}
+
+// ModifierIndex finds the modifiers of an entity.
+const ModifierIndex = "game.Modifier"
+
+// Modifier is its own entity, like a buff or a worn item, and adds to the stats of Target.
+type Modifier struct {
+	ecs.ComponentHeader[Modifier, *Modifier]
+	Target     ecs.EntityID
+	Accuracy   int
+	Damage     int
+	CritChance int
+	Evasion    int
+	Armor      int
+	Resistance map[DamageType]int
+}
+
+func (m Modifier) Index() []ecs.Indexer {
+	return []ecs.Indexer{
+		ecs.EQ(ModifierIndex, m.Target),
+	}
+}
+
+// LeavesCorpse marks entities that turn into a Corpse when they die, instead of being removed.
+type LeavesCorpse struct {
+	ecs.ComponentHeader[LeavesCorpse, *LeavesCorpse]
+}
+
+type Corpse struct {
+	ecs.ComponentHeader[Corpse, *Corpse]
+}
+
+// Hit chances are clamped, so every attack can hit or miss.
+const (
+	MinHitChance          = 5
+	MaxHitChance          = 95
+	DefaultCritMultiplier = 2
+)
+
+// modifiers sums the modifiers targeting id.
+func modifiers(db *ecs.DB, id ecs.EntityID) Modifier {
+	var sum, m Modifier
+	for range db.Search().Components(&m).Index(ecs.EQ(ModifierIndex, id)).Done() {
+		sum.Accuracy += m.Accuracy
+		sum.Damage += m.Damage
+		sum.CritChance += m.CritChance
+		sum.Evasion += m.Evasion
+		sum.Armor += m.Armor
+		for t, percent := range m.Resistance {
+			if sum.Resistance == nil {
+				sum.Resistance = make(map[DamageType]int)
+			}
+			sum.Resistance[t] += percent
+		}
+	}
+	return sum
+}
+
+// HitChance returns the chance in percent of actor hitting target, or false if actor cannot attack.
+func HitChance(db *ecs.DB, actor, target ecs.EntityID) (int, bool) {
+	var offense Offense
+	if !db.Get(actor, &offense) {
+		return 0, false
+	}
+	var defense Defense
+	db.Get(target, &defense)
+	chance := offense.Accuracy + modifiers(db, actor).Accuracy - defense.Evasion - modifiers(db, target).Evasion
+	return min(max(chance, MinHitChance), MaxHitChance), true
+}
+
+// Resolve rolls an attack of actor on target on the combat stream, and applies the damage.
+// Nothing happens if actor has no Offense, target has no Health, or w has no RNG.
+func Resolve(w *World, actor, target ecs.EntityID) []Event {
+	var offense Offense
+	var health Health
+	if w.RNG == nil || !w.DB.Get(actor, &offense) || !w.DB.Get(target, &health) {
+		return nil
+	}
+	s := w.RNG.Stream(rng.Combat)
+	chance, _ := HitChance(w.DB, actor, target)
+	if s.IntN(100) >= chance {
+		return []Event{Missed{Actor: actor, Target: target}}
+	}
+	attackMods := modifiers(w.DB, actor)
+	damage := offense.Damage.Roll(s) + attackMods.Damage
+	crit := s.IntN(100) < offense.CritChance+attackMods.CritChance
+	if crit {
+		multiplier := offense.CritMultiplier
+		if multiplier == 0 {
+			multiplier = DefaultCritMultiplier
+		}
+		damage *= multiplier
+	}
+	damage = mitigate(w.DB, target, offense.Type, damage)
+	health.Current -= damage
+	w.DB.Set(target, health)
+	events := []Event{Hit{Actor: actor, Target: target, Damage: damage, Type: offense.Type, Crit: crit}}
+	if health.Current <= 0 {
+		events = append(events, Kill(w.DB, target))
+	}
+	return events
+}
+
+// mitigate applies the target's resistances, and armor for physical damage.
+// Damage never goes below zero.
+func mitigate(db *ecs.DB, target ecs.EntityID, t DamageType, damage int) int {
+	var defense Defense
+	var resistances Resistances
+	db.Get(target, &defense)
+	db.Get(target, &resistances)
+	mods := modifiers(db, target)
+	resist := min(resistances.Percent[t]+mods.Resistance[t], 100)
+	damage -= damage * resist / 100
+	if t == Physical {
+		damage -= defense.Armor + mods.Armor
+	}
+	return max(damage, 0)
+}
+
+// Kill removes target, or turns it into a Corpse if it has LeavesCorpse.
+// A corpse keeps only its GridLocation. Its modifiers are removed either way,
+// and the items it carries are dropped where it stood, or removed if it had no location.
+func Kill(db *ecs.DB, target ecs.EntityID) Died {
+	var m Modifier
+	for _, id := range rang.ToSlice(db.Search().Components(&m).Index(ecs.EQ(ModifierIndex, target)).Done()) {
+		db.Remove(id)
+	}
+	var loc GridLocation
+	hasLoc := db.Get(target, &loc)
+	for _, id := range rang.ToSlice(db.Search().Index(ecs.EQ(CarriedIndex, target)).Done()) {
+		if !hasLoc {
+			db.Remove(id)
+			continue
+		}
+		db.Unset(id, Carried{})
+		db.Set(id, loc)
+	}
+	if !db.Get(target, &LeavesCorpse{}) {
+		db.Remove(target)
+		return Died{Target: target}
+	}
+	db.Clear(target)
+	if hasLoc {
+		db.Set(target, loc)
+	}
+	db.Set(target, Corpse{})
+	return Died{Target: target, Corpse: true}
+}
+
+type Missed struct {
+	Actor, Target ecs.EntityID
+}
+
+func (e Missed) String() string {
+	return fmt.Sprintf("%v missed %v", e.Actor, e.Target)
+}
+
+type Hit struct {
+	Actor, Target ecs.EntityID
+	Damage        int
+	Type          DamageType
+	Crit          bool
+}
+
+func (e Hit) String() string {
+	if e.Crit {
+		return fmt.Sprintf("%v critically hit %v for %d %v damage", e.Actor, e.Target, e.Damage, e.Type)
+	}
+	return fmt.Sprintf("%v hit %v for %d %v damage", e.Actor, e.Target, e.Damage, e.Type)
+}
+
+type Died struct {
+	Target ecs.EntityID
+	// Corpse is true if the target was turned into a corpse instead of removed.
+	Corpse bool
+}
+
+func (e Died) String() string {
+	return fmt.Sprintf("%v died", e.Target)
+}
@@ -0,0 +1,189 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/PieterD/boevig/ecs"
+	"github.com/PieterD/boevig/game/rng"
+	"github.com/PieterD/boevig/rang"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHitChance(t *testing.T) {
+	db := ecs.New()
+	hero := db.NewEntity(Offense{Accuracy: 70})
+	rat := db.NewEntity(Defense{Evasion: 20})
+	ghost := db.NewEntity(Defense{Evasion: 200})
+	chance, ok := HitChance(db, hero, rat)
+	require.True(t, ok)
+	require.Equal(t, 50, chance)
+	chance, _ = HitChance(db, hero, ghost)
+	require.Equal(t, MinHitChance, chance)
+	chance, _ = HitChance(db, hero, db.NewEntity())
+	require.Equal(t, 70, chance)
+	_, ok = HitChance(db, rat, hero)
+	require.False(t, ok)
+
+	db.NewEntity(Modifier{Target: hero, Accuracy: 100})
+	db.NewEntity(Modifier{Target: rat, Evasion: 5})
+	chance, _ = HitChance(db, hero, rat)
+	require.Equal(t, MaxHitChance, chance)
+	db.NewEntity(Modifier{Target: rat, Evasion: 100})
+	chance, _ = HitChance(db, hero, rat)
+	require.Equal(t, 45, chance)
+}
+
+func TestMitigate(t *testing.T) {
+	db := ecs.New()
+	target := db.NewEntity(
+		Defense{Armor: 2},
+		Resistances{Percent: map[DamageType]int{Fire: 50, Cold: -50}},
+	)
+	for _, test := range []struct {
+		typ    DamageType
+		damage int
+		want   int
+	}{
+		{typ: Physical, damage: 10, want: 8},
+		{typ: Physical, damage: 1, want: 0},
+		{typ: Fire, damage: 10, want: 5},
+		{typ: Cold, damage: 10, want: 15},
+		{typ: Poison, damage: 10, want: 10},
+	} {
+		t.Run(test.typ.String(), func(t *testing.T) {
+			require.Equal(t, test.want, mitigate(db, target, test.typ, test.damage))
+		})
+	}
+	db.NewEntity(Modifier{Target: target, Armor: 3, Resistance: map[DamageType]int{Fire: 60, Poison: 10}})
+	require.Equal(t, 5, mitigate(db, target, Physical, 10))
+	require.Equal(t, 0, mitigate(db, target, Fire, 10))
+	require.Equal(t, 9, mitigate(db, target, Poison, 10))
+}
+
+func TestKill(t *testing.T) {
+	db := ecs.New()
+	rat := db.NewEntity(GridLocation{X: 1, Y: 1}, Health{Current: 0, Max: 3}, Blocking{})
+	skeleton := db.NewEntity(GridLocation{X: 2, Y: 1}, Health{Current: 0, Max: 3}, Blocking{}, Speed{Value: 10}, LeavesCorpse{})
+	buff := db.NewEntity(Modifier{Target: skeleton, Armor: 1})
+	require.Equal(t, Died{Target: rat}, Kill(db, rat))
+	require.False(t, db.Get(rat, &GridLocation{}))
+	require.Equal(t, Died{Target: skeleton, Corpse: true}, Kill(db, skeleton))
+	require.True(t, db.Get(skeleton, &Corpse{}, &GridLocation{}))
+	require.False(t, db.Get(skeleton, &Health{}))
+	require.False(t, db.Get(skeleton, &Blocking{}))
+	require.False(t, db.Get(skeleton, &Speed{}))
+	require.False(t, db.Get(buff, &Modifier{}))
+
+	t.Run("corpse keeps only its location", func(t *testing.T) {
+		db := ecs.New()
+		type Name struct {
+			ecs.ComponentHeader[Name, *Name]
+			Name string
+		}
+		loc := GridLocation{X: 3, Y: 2}
+		zombie := db.NewEntity(loc, Health{Max: 3}, Resistances{Percent: map[DamageType]int{Fire: 50}}, Name{Name: "zombie"}, LeavesCorpse{})
+		Kill(db, zombie)
+		require.True(t, db.Get(zombie, &Corpse{}))
+		require.False(t, db.Get(zombie, &Resistances{}))
+		require.False(t, db.Get(zombie, &Name{}))
+		require.False(t, db.Get(zombie, &LeavesCorpse{}))
+		var got GridLocation
+		require.True(t, db.Get(zombie, &got))
+		require.Equal(t, loc, got)
+	})
+	t.Run("carried items are dropped", func(t *testing.T) {
+		db := ecs.New()
+		loc := GridLocation{X: 1, Y: 1}
+		goblin := db.NewEntity(loc, Health{Max: 3})
+		sword := db.NewEntity(Item{Name: "sword"}, Carried{By: goblin})
+		ghost := db.NewEntity(Health{Max: 3})
+		orb := db.NewEntity(Item{Name: "orb"}, Carried{By: ghost})
+		Kill(db, goblin)
+		Kill(db, ghost)
+		var got GridLocation
+		require.True(t, db.Get(sword, &got))
+		require.Equal(t, loc, got)
+		require.False(t, db.Get(sword, &Carried{}))
+		require.True(t, db.Get(sword, &Item{}))
+		require.False(t, db.Get(orb, &Item{}))
+		require.Empty(t, rang.ToSlice(db.SearchIndex(ecs.EQ(CarriedIndex, goblin))))
+	})
+}
+
+func fight(t *testing.T, seed uint64) []string {
+	w := testWorld(t)
+	w.RNG = rng.New(seed)
+	hero := w.DB.NewEntity(GridLocation{X: 1, Y: 1}, Offense{Accuracy: 60, Damage: rng.MustParseDice("1d6+1"), CritChance: 10})
+	orc := w.DB.NewEntity(GridLocation{X: 2, Y: 1}, Health{Current: 30, Max: 30}, Blocking{}, LeavesCorpse{})
+	var log []string
+	for !w.DB.Get(orc, &Corpse{}) {
+		result, err := Perform(w, hero, Move{Dir: E})
+		require.NoError(t, err)
+		for _, event := range result.Events {
+			log = append(log, event.String())
+		}
+	}
+	return log
+}
+
+func TestResolve(t *testing.T) {
+	w := testWorld(t)
+	hero := w.DB.NewEntity(GridLocation{X: 1, Y: 1}, Offense{Accuracy: 1000, Damage: rng.MustParseDice("2d4"), Type: Fire})
+	orc := w.DB.NewEntity(GridLocation{X: 2, Y: 1}, Health{Current: 1000, Max: 1000}, Blocking{})
+	hits, misses := 0, 0
+	for range 200 {
+		var health Health
+		require.True(t, w.DB.Get(orc, &health))
+		events := Resolve(w, hero, orc)
+		require.Len(t, events, 1)
+		switch event := events[0].(type) {
+		case Hit:
+			hits++
+			require.Equal(t, Fire, event.Type)
+			require.False(t, event.Crit)
+			require.GreaterOrEqual(t, event.Damage, 2)
+			require.LessOrEqual(t, event.Damage, 8)
+			before := health.Current
+			require.True(t, w.DB.Get(orc, &health))
+			require.Equal(t, before-event.Damage, health.Current)
+		case Missed:
+			misses++
+		}
+	}
+	require.InDelta(t, 190, hits, 15)
+	require.Equal(t, 200, hits+misses)
+	require.Nil(t, Resolve(w, orc, hero))
+
+	log := fight(t, 3)
+	require.Equal(t, log, fight(t, 3))
+	require.NotEqual(t, log, fight(t, 4))
+	require.Contains(t, log, "2 died")
+	require.Contains(t, log, "1 attacked 2")
+}
+
+func TestWorld_NoRNG(t *testing.T) {
+	require.Panics(t, func() { NewWorld(ecs.New(), NewMap(1, 1), nil) })
+	w := &World{DB: ecs.New(), Map: NewMap(3, 3)}
+	hero := w.DB.NewEntity(Offense{Accuracy: 100, Damage: rng.MustParseDice("1d4")})
+	orc := w.DB.NewEntity(Health{Current: 5, Max: 5})
+	require.Nil(t, Resolve(w, hero, orc))
+}
+
+func TestResolve_Crit(t *testing.T) {
+	w := testWorld(t)
+	hero := w.DB.NewEntity(Offense{Accuracy: 100, Damage: rng.MustParseDice("1d1+4"), CritChance: 100, CritMultiplier: 3})
+	rat := w.DB.NewEntity(Health{Current: 10, Max: 10})
+	for {
+		events := Resolve(w, hero, rat)
+		if _, ok := events[0].(Missed); ok {
+			continue
+		}
+		require.Equal(t, []Event{
+			Hit{Actor: hero, Target: rat, Damage: 15, Type: Physical, Crit: true},
+			Died{Target: rat},
+		}, events)
+		require.Equal(t, "1 critically hit 2 for 15 physical damage", events[0].String())
+		break
+	}
+	require.False(t, w.DB.Get(rat, &Health{}))
+}
@@ -0,0 +1,235 @@
+package game
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/PieterD/boevig/ecs"
+)
+
+// DijkstraMap holds, for every tile, the distance to the nearest goal.
+// Monsters walk Downhill to approach the goals, and downhill on a Flee map to get away from them.
+// Every step costs 1, diagonal steps included when the map is 8-connected.
+type DijkstraMap struct {
+	m        *Map
+	diagonal bool
+	seeds    []float64
+	values   []float64
+	queue    dijkstraQueue
+}
+
+func NewDijkstraMap(m *Map, diagonal bool) *DijkstraMap {
+	n := m.Width() * m.Height()
+	dm := &DijkstraMap{
+		m:        m,
+		diagonal: diagonal,
+		seeds:    make([]float64, n),
+		values:   make([]float64, n),
+	}
+	dm.ClearGoals()
+	return dm
+}
+
+// SetGoal seeds loc with value, lower values attract more. It takes effect on the next Scan.
+func (dm *DijkstraMap) SetGoal(loc GridLocation, value float64) {
+	if dm.m.InBounds(loc) {
+		i := dm.index(loc)
+		dm.seeds[i] = min(dm.seeds[i], value)
+	}
+}
+
+// SetGoalEntities makes every entity in db with a GridLocation and all of the given components a goal.
+func (dm *DijkstraMap) SetGoalEntities(db *ecs.DB, componentPtrs ...ecs.Component) {
+	var loc GridLocation
+	for range db.Search().Components(append([]ecs.Component{&loc}, componentPtrs...)...).Done() {
+		dm.SetGoal(loc, 0)
+	}
+}
+
+func (dm *DijkstraMap) ClearGoals() {
+	for i := range dm.seeds {
+		dm.seeds[i] = math.Inf(1)
+	}
+}
+
+// Scan computes the whole map from its goals.
+func (dm *DijkstraMap) Scan() {
+	copy(dm.values, dm.seeds)
+	dm.queue = dm.queue[:0]
+	for i, v := range dm.values {
+		if math.IsInf(v, 1) {
+			continue
+		}
+		if !dm.m.Passable(dm.location(i)) {
+			dm.values[i] = math.Inf(1)
+			continue
+		}
+		dm.queue = append(dm.queue, dijkstraItem{index: i, value: v})
+	}
+	heap.Init(&dm.queue)
+	dm.propagate()
+}
+
+// TerrainChanged updates the map after the terrain at loc changed,
+// only recomputing the tiles whose distance depends on it.
+func (dm *DijkstraMap) TerrainChanged(loc GridLocation) {
+	if !dm.m.InBounds(loc) {
+		return
+	}
+	dm.queue = dm.queue[:0]
+	if dm.m.Passable(loc) {
+		i := dm.index(loc)
+		dm.values[i] = dm.seeds[i]
+		for _, next := range dm.neighbours(loc) {
+			dm.values[i] = min(dm.values[i], dm.values[dm.index(next)]+1)
+		}
+		dm.push(i)
+		dm.propagate()
+		return
+	}
+	affected := dm.dependents(loc)
+	for i := range affected {
+		dm.values[i] = dm.seeds[i]
+		if !dm.m.Passable(dm.location(i)) {
+			dm.values[i] = math.Inf(1)
+		}
+	}
+	for i := range affected {
+		if !math.IsInf(dm.values[i], 1) {
+			dm.push(i)
+		}
+		for _, next := range dm.neighbours(dm.location(i)) {
+			if j := dm.index(next); !affected[j] {
+				dm.push(j)
+			}
+		}
+	}
+	dm.propagate()
+}
+
+// dependents returns loc and every tile whose distance may have been reached through it.
+func (dm *DijkstraMap) dependents(loc GridLocation) map[int]bool {
+	start := dm.index(loc)
+	affected := map[int]bool{start: true}
+	if math.IsInf(dm.values[start], 1) {
+		return affected
+	}
+	stack := []int{start}
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, next := range dm.neighbours(dm.location(i)) {
+			j := dm.index(next)
+			if affected[j] || dm.values[j] != dm.values[i]+1 {
+				continue
+			}
+			affected[j] = true
+			stack = append(stack, j)
+		}
+	}
+	return affected
+}
+
+func (dm *DijkstraMap) push(i int) {
+	heap.Push(&dm.queue, dijkstraItem{index: i, value: dm.values[i]})
+}
+
+func (dm *DijkstraMap) propagate() {
+	for dm.queue.Len() > 0 {
+		item := heap.Pop(&dm.queue).(dijkstraItem)
+		if item.value > dm.values[item.index] {
+			continue
+		}
+		for _, next := range dm.neighbours(dm.location(item.index)) {
+			j := dm.index(next)
+			if v := item.value + 1; v < dm.values[j] {
+				dm.values[j] = v
+				dm.push(j)
+			}
+		}
+	}
+}
+
+// Flee returns a map that leads away from the goals. Every distance is multiplied by -coefficient
+// and scanned again, so fleeing monsters head for open space rather than into corners.
+// A coefficient around 1.2 works well.
+func (dm *DijkstraMap) Flee(coefficient float64) *DijkstraMap {
+	flee := NewDijkstraMap(dm.m, dm.diagonal)
+	for i, v := range dm.values {
+		if !math.IsInf(v, 1) {
+			flee.seeds[i] = -coefficient * v
+		}
+	}
+	flee.Scan()
+	return flee
+}
+
+// Value returns the distance from loc to the nearest goal, or +Inf if none can be reached.
+func (dm *DijkstraMap) Value(loc GridLocation) float64 {
+	if !dm.m.InBounds(loc) {
+		return math.Inf(1)
+	}
+	return dm.values[dm.index(loc)]
+}
+
+// Downhill returns the direction of the lowest neighbour of from that is lower than from itself,
+// or zero if there is none.
+func (dm *DijkstraMap) Downhill(from GridLocation) Direction {
+	var best Direction
+	lowest := dm.Value(from)
+	for _, d := range dm.directions() {
+		if v := dm.Value(from.Step(d)); v < lowest {
+			best, lowest = d, v
+		}
+	}
+	return best
+}
+
+func (dm *DijkstraMap) directions() []Direction {
+	if dm.diagonal {
+		return Directions8
+	}
+	return Directions4
+}
+
+// neighbours returns the passable locations one step from loc.
+func (dm *DijkstraMap) neighbours(loc GridLocation) []GridLocation {
+	var buf [8]GridLocation
+	locs := buf[:0]
+	for _, d := range dm.directions() {
+		if next := loc.Step(d); dm.m.Passable(next) {
+			locs = append(locs, next)
+		}
+	}
+	return locs
+}
+
+func (dm *DijkstraMap) index(loc GridLocation) int {
+	return loc.Y*dm.m.Width() + loc.X
+}
+
+func (dm *DijkstraMap) location(i int) GridLocation {
+	return GridLocation{X: i % dm.m.Width(), Y: i / dm.m.Width()}
+}
+
+type dijkstraItem struct {
+	index int
+	value float64
+}
+
+type dijkstraQueue []dijkstraItem
+
+func (q dijkstraQueue) Len() int           { return len(q) }
+func (q dijkstraQueue) Less(i, j int) bool { return q[i].value < q[j].value }
+func (q dijkstraQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *dijkstraQueue) Push(x any) {
+	*q = append(*q, x.(dijkstraItem))
+}
+
+func (q *dijkstraQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,121 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/PieterD/boevig/ecs"
+	"github.com/stretchr/testify/require"
+)
+
+func dijkstraValues(dm *DijkstraMap) [][]float64 {
+	var rows [][]float64
+	for y := 0; y < dm.m.Height(); y++ {
+		var row []float64
+		for x := 0; x < dm.m.Width(); x++ {
+			row = append(row, dm.Value(GridLocation{X: x, Y: y}))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestDijkstraMap(t *testing.T) {
+	inf := math.Inf(1)
+	m := testMap(t,
+		"######",
+		"#....#",
+		"#.##.#",
+		"#....#",
+		"######",
+	)
+	t.Run("scan", func(t *testing.T) {
+		dm := NewDijkstraMap(m, false)
+		dm.SetGoal(GridLocation{X: 1, Y: 1}, 0)
+		dm.Scan()
+		require.Equal(t, [][]float64{
+			{inf, inf, inf, inf, inf, inf},
+			{inf, 0, 1, 2, 3, inf},
+			{inf, 1, inf, inf, 4, inf},
+			{inf, 2, 3, 4, 5, inf},
+			{inf, inf, inf, inf, inf, inf},
+		}, dijkstraValues(dm))
+		require.Equal(t, W, dm.Downhill(GridLocation{X: 2, Y: 1}))
+		require.Equal(t, Direction(0), dm.Downhill(GridLocation{X: 1, Y: 1}))
+	})
+	t.Run("diagonal", func(t *testing.T) {
+		dm := NewDijkstraMap(m, true)
+		dm.SetGoal(GridLocation{X: 1, Y: 1}, 0)
+		dm.Scan()
+		require.Equal(t, 4.0, dm.Value(GridLocation{X: 4, Y: 3}))
+		require.Equal(t, NW, dm.Downhill(GridLocation{X: 2, Y: 3}))
+	})
+	t.Run("goal entities", func(t *testing.T) {
+		type Loot struct {
+			ecs.ComponentHeader[Loot, *Loot]
+		}
+		db := ecs.New()
+		db.NewEntity(GridLocation{X: 4, Y: 3}, Loot{})
+		db.NewEntity(GridLocation{X: 1, Y: 3}, Loot{})
+		db.NewEntity(GridLocation{X: 1, Y: 1})
+		dm := NewDijkstraMap(m, false)
+		var loot Loot
+		dm.SetGoalEntities(db, &loot)
+		dm.Scan()
+		require.Equal(t, 2.0, dm.Value(GridLocation{X: 1, Y: 1}))
+		require.Equal(t, 0.0, dm.Value(GridLocation{X: 4, Y: 3}))
+		require.Equal(t, 1.0, dm.Value(GridLocation{X: 2, Y: 3}))
+	})
+	t.Run("flee", func(t *testing.T) {
+		corridor := testMap(t, "#.......#")
+		dm := NewDijkstraMap(corridor, false)
+		dm.SetGoal(GridLocation{X: 3, Y: 0}, 0)
+		dm.Scan()
+		flee := dm.Flee(1.2)
+		require.Equal(t, E, flee.Downhill(GridLocation{X: 4, Y: 0}))
+		require.Equal(t, W, flee.Downhill(GridLocation{X: 2, Y: 0}))
+		require.Equal(t, Direction(0), flee.Downhill(GridLocation{X: 7, Y: 0}))
+	})
+	t.Run("unreachable", func(t *testing.T) {
+		dm := NewDijkstraMap(m, false)
+		dm.Scan()
+		require.True(t, math.IsInf(dm.Value(GridLocation{X: 1, Y: 1}), 1))
+		require.Equal(t, Direction(0), dm.Downhill(GridLocation{X: 1, Y: 1}))
+	})
+}
+
+func TestDijkstraMap_TerrainChanged(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, diagonal := range []bool{false, true} {
+		m := NewMap(12, 10)
+		for y := 0; y < m.Height(); y++ {
+			for x := 0; x < m.Width(); x++ {
+				if rnd.Intn(4) > 0 {
+					m.SetTile(GridLocation{X: x, Y: y}, Floor)
+				}
+			}
+		}
+		goals := []GridLocation{{X: 2, Y: 2}, {X: 9, Y: 7}}
+		incremental := NewDijkstraMap(m, diagonal)
+		for i, goal := range goals {
+			incremental.SetGoal(goal, float64(i))
+		}
+		incremental.Scan()
+		for i := 0; i < 200; i++ {
+			loc := GridLocation{X: rnd.Intn(m.Width()), Y: rnd.Intn(m.Height())}
+			tile := Floor
+			if m.Passable(loc) {
+				tile = Wall
+			}
+			m.SetTile(loc, tile)
+			incremental.TerrainChanged(loc)
+			full := NewDijkstraMap(m, diagonal)
+			for i, goal := range goals {
+				full.SetGoal(goal, float64(i))
+			}
+			full.Scan()
+			require.Equal(t, dijkstraValues(full), dijkstraValues(incremental), "change %d at %v to %v", i, loc, tile)
+		}
+	}
+}
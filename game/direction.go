@@ -0,0 +1,164 @@
+package game
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+)
+
+var (
+	// Directions4 lists the orthogonal directions clockwise from north.
+	Directions4 = []Direction{N, E, S, W}
+	// Directions8 lists all directions clockwise from north.
+	Directions8 = []Direction{N, NE, E, SE, S, SW, W, NW}
+)
+
+var directionNames = map[Direction][]string{
+	N:  {"N", "north"},
+	NE: {"NE", "northeast"},
+	E:  {"E", "east"},
+	SE: {"SE", "southeast"},
+	S:  {"S", "south"},
+	SW: {"SW", "southwest"},
+	W:  {"W", "west"},
+	NW: {"NW", "northwest"},
+}
+
+// Valid reports whether d is one of the eight directions.
+// Zero, unknown bits and contradictions like N|S are invalid.
+func (d Direction) Valid() bool {
+	return d.index() >= 0
+}
+
+func (d Direction) index() int {
+	for i, dir := range Directions8 {
+		if dir == d {
+			return i
+		}
+	}
+	return -1
+}
+
+// Delta returns the change in coordinates of a step in direction d, with north being negative y.
+// It panics on invalid directions, like Rotate.
+func (d Direction) Delta() (dx, dy int) {
+	if !d.Valid() {
+		panic(fmt.Errorf("stepping %v: invalid direction", d))
+	}
+	if d&N != 0 {
+		dy--
+	}
+	if d&S != 0 {
+		dy++
+	}
+	if d&E != 0 {
+		dx++
+	}
+	if d&W != 0 {
+		dx--
+	}
+	return dx, dy
+}
+
+func (d Direction) Opposite() Direction {
+	return d.Rotate(4)
+}
+
+// Rotate turns d clockwise by steps of 45 degrees, negative steps turn counterclockwise.
+func (d Direction) Rotate(steps int) Direction {
+	i := d.index()
+	if i < 0 {
+		panic(fmt.Errorf("rotating %v: invalid direction", d))
+	}
+	n := len(Directions8)
+	return Directions8[((i+steps)%n+n)%n]
+}
+
+func (d Direction) Left45() Direction {
+	return d.Rotate(-1)
+}
+
+func (d Direction) Right45() Direction {
+	return d.Rotate(1)
+}
+
+func (d Direction) Left90() Direction {
+	return d.Rotate(-2)
+}
+
+func (d Direction) Right90() Direction {
+	return d.Rotate(2)
+}
+
+// DirectionBetween returns the direction of the first step from a towards b,
+// or zero if they are the same location.
+func DirectionBetween(a, b GridLocation) Direction {
+	var d Direction
+	switch {
+	case b.Y < a.Y:
+		d |= N
+	case b.Y > a.Y:
+		d |= S
+	}
+	switch {
+	case b.X > a.X:
+		d |= E
+	case b.X < a.X:
+		d |= W
+	}
+	return d
+}
+
+func (loc GridLocation) Neighbours4() iter.Seq2[Direction, GridLocation] {
+	return loc.neighbours(Directions4)
+}
+
+func (loc GridLocation) Neighbours8() iter.Seq2[Direction, GridLocation] {
+	return loc.neighbours(Directions8)
+}
+
+func (loc GridLocation) neighbours(dirs []Direction) iter.Seq2[Direction, GridLocation] {
+	return func(yield func(Direction, GridLocation) bool) {
+		for _, d := range dirs {
+			if !yield(d, loc.Step(d)) {
+				return
+			}
+		}
+	}
+}
+
+func (d Direction) String() string {
+	names, ok := directionNames[d]
+	if !ok {
+		return fmt.Sprintf("Direction(%d)", int(d))
+	}
+	return names[0]
+}
+
+// ParseDirection accepts short and long names in any case, like "ne" or "Northeast".
+func ParseDirection(s string) (Direction, error) {
+	for d, names := range directionNames {
+		for _, name := range names {
+			if strings.EqualFold(s, name) {
+				return d, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("parsing direction %q: unknown direction", s)
+}
+
+func (d Direction) MarshalText() ([]byte, error) {
+	if !d.Valid() {
+		return nil, fmt.Errorf("marshaling %v: invalid direction", d)
+	}
+	return []byte(d.String()), nil
+}
+
+func (d *Direction) UnmarshalText(text []byte) error {
+	parsed, err := ParseDirection(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
@@ -0,0 +1,86 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirection(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		for _, d := range Directions8 {
+			require.True(t, d.Valid(), "%v", d)
+		}
+		for _, d := range []Direction{0, N | S, E | W, N | E | S, 16} {
+			require.False(t, d.Valid(), "%v", d)
+		}
+	})
+	t.Run("delta", func(t *testing.T) {
+		dx, dy := NE.Delta()
+		require.Equal(t, []int{1, -1}, []int{dx, dy})
+		dx, dy = W.Delta()
+		require.Equal(t, []int{-1, 0}, []int{dx, dy})
+		require.Equal(t, GridLocation{X: 2, Y: 4}, GridLocation{X: 3, Y: 3}.Step(SW))
+		require.Panics(t, func() { (N | S).Delta() })
+		require.Panics(t, func() { GridLocation{}.Step(0) })
+	})
+	t.Run("rotate", func(t *testing.T) {
+		require.Equal(t, S, N.Opposite())
+		require.Equal(t, NW, SE.Opposite())
+		require.Equal(t, NE, N.Right45())
+		require.Equal(t, NW, N.Left45())
+		require.Equal(t, E, N.Right90())
+		require.Equal(t, SW, NW.Left90())
+		require.Equal(t, W, E.Rotate(12))
+		require.Equal(t, W, E.Rotate(-4))
+		require.Panics(t, func() { (N | S).Rotate(1) })
+	})
+	t.Run("between", func(t *testing.T) {
+		a := GridLocation{X: 5, Y: 5}
+		require.Equal(t, Direction(0), DirectionBetween(a, a))
+		require.Equal(t, N, DirectionBetween(a, GridLocation{X: 5, Y: 1}))
+		require.Equal(t, SE, DirectionBetween(a, GridLocation{X: 9, Y: 6}))
+		require.Equal(t, W, DirectionBetween(a, GridLocation{X: 0, Y: 5}))
+		for _, d := range Directions8 {
+			require.Equal(t, d, DirectionBetween(a, a.Step(d)))
+		}
+	})
+	t.Run("neighbourhoods", func(t *testing.T) {
+		var dirs []Direction
+		var locs []GridLocation
+		for d, loc := range (GridLocation{X: 1, Y: 1}).Neighbours4() {
+			dirs = append(dirs, d)
+			locs = append(locs, loc)
+		}
+		require.Equal(t, Directions4, dirs)
+		require.Equal(t, []GridLocation{{X: 1, Y: 0}, {X: 2, Y: 1}, {X: 1, Y: 2}, {X: 0, Y: 1}}, locs)
+		n := 0
+		for range (GridLocation{}).Neighbours8() {
+			n++
+		}
+		require.Equal(t, 8, n)
+	})
+	t.Run("names", func(t *testing.T) {
+		require.Equal(t, "NE", NE.String())
+		require.Equal(t, "Direction(5)", (N | S).String())
+		for _, s := range []string{"sw", "SW", "southwest", "SouthWest"} {
+			d, err := ParseDirection(s)
+			require.NoError(t, err)
+			require.Equal(t, SW, d)
+		}
+		_, err := ParseDirection("up")
+		require.Error(t, err)
+	})
+	t.Run("text", func(t *testing.T) {
+		bindings := map[string]Direction{"k": N, "l": E}
+		data, err := json.Marshal(bindings)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"k":"N","l":"E"}`, string(data))
+		var got map[string]Direction
+		require.NoError(t, json.Unmarshal([]byte(`{"k":"north","b":"sw"}`), &got))
+		require.Equal(t, map[string]Direction{"k": N, "b": SW}, got)
+		_, err = json.Marshal(N | S)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,257 @@
+package game
+
+import (
+	"iter"
+
+	"github.com/PieterD/boevig/ecs"
+)
+
+// BlocksSight marks entities that cannot be seen through, like boulders or smoke.
+type BlocksSight struct {
+	ecs.ComponentHeader[BlocksSight, *BlocksSight]
+}
+
+// Sight computes what can be seen on a map, through transparent terrain
+// and past entities that are not blocking.
+type Sight struct {
+	m       *Map
+	blocked map[GridLocation]bool
+}
+
+func NewSight(m *Map) *Sight {
+	return &Sight{
+		m:       m,
+		blocked: make(map[GridLocation]bool),
+	}
+}
+
+// BlockEntities blocks sight at the location of every entity in db that has a GridLocation and a BlocksSight.
+func (s *Sight) BlockEntities(db *ecs.DB) {
+	var loc GridLocation
+	var blocks BlocksSight
+	for range db.Search().Components(&loc, &blocks).Done() {
+		s.Block(loc)
+	}
+}
+
+func (s *Sight) Block(loc GridLocation) {
+	s.blocked[loc] = true
+}
+
+func (s *Sight) ClearBlockers() {
+	clear(s.blocked)
+}
+
+func (s *Sight) Opaque(loc GridLocation) bool {
+	return !s.m.Transparent(loc) || s.blocked[loc]
+}
+
+// Visibility is the set of tiles a viewer can see.
+type Visibility struct {
+	width   int
+	visible []bool
+}
+
+func (v *Visibility) Visible(loc GridLocation) bool {
+	if loc.X < 0 || loc.Y < 0 || loc.X >= v.width || loc.Y*v.width+loc.X >= len(v.visible) {
+		return false
+	}
+	return v.visible[loc.Y*v.width+loc.X]
+}
+
+func (v *Visibility) All() iter.Seq[GridLocation] {
+	return func(yield func(GridLocation) bool) {
+		for i, visible := range v.visible {
+			if !visible {
+				continue
+			}
+			if !yield(GridLocation{X: i % v.width, Y: i / v.width}) {
+				return
+			}
+		}
+	}
+}
+
+func (v *Visibility) reveal(loc GridLocation) {
+	v.visible[loc.Y*v.width+loc.X] = true
+}
+
+// FOV returns the tiles visible from origin within radius, using symmetric shadowcasting:
+// a tile is visible from origin exactly when origin is visible from it.
+// Opaque tiles are visible themselves, but hide what is behind them. A radius of zero is unlimited.
+func (s *Sight) FOV(origin GridLocation, radius int) *Visibility {
+	v := &Visibility{
+		width:   s.m.Width(),
+		visible: make([]bool, s.m.Width()*s.m.Height()),
+	}
+	if !s.m.InBounds(origin) {
+		return v
+	}
+	if radius <= 0 {
+		radius = s.m.Width() + s.m.Height()
+	}
+	v.reveal(origin)
+	for _, q := range quadrants {
+		scan := fovScan{sight: s, v: v, origin: origin, radius: radius, quadrant: q}
+		scan.row(1, slope{-1, 1}, slope{1, 1})
+	}
+	return v
+}
+
+// quadrant maps a depth and column in the scan to a step from the origin.
+type quadrant struct {
+	depthX, depthY int
+	colX, colY     int
+}
+
+var quadrants = []quadrant{
+	{depthY: -1, colX: 1},
+	{depthX: 1, colY: 1},
+	{depthY: 1, colX: 1},
+	{depthX: -1, colY: 1},
+}
+
+// slope is an exact fraction, num/den with a positive den.
+type slope struct {
+	num, den int
+}
+
+type fovScan struct {
+	sight    *Sight
+	v        *Visibility
+	origin   GridLocation
+	radius   int
+	quadrant quadrant
+}
+
+func (scan *fovScan) location(depth, col int) GridLocation {
+	q := scan.quadrant
+	return GridLocation{
+		X: scan.origin.X + depth*q.depthX + col*q.colX,
+		Y: scan.origin.Y + depth*q.depthY + col*q.colY,
+	}
+}
+
+func (scan *fovScan) row(depth int, start, end slope) {
+	if depth > scan.radius {
+		return
+	}
+	// Columns run from depth*start rounded half up to depth*end rounded half down.
+	minCol := floorDiv(2*depth*start.num+start.den, 2*start.den)
+	maxCol := -floorDiv(-2*depth*end.num+end.den, 2*end.den)
+	prevWall, first := false, true
+	for col := minCol; col <= maxCol; col++ {
+		loc := scan.location(depth, col)
+		wall := scan.sight.Opaque(loc)
+		symmetric := col*start.den >= depth*start.num && col*end.den <= depth*end.num
+		if (wall || symmetric) && scan.sight.m.InBounds(loc) && depth*depth+col*col <= scan.radius*scan.radius {
+			scan.v.reveal(loc)
+		}
+		if !first && prevWall && !wall {
+			start = slope{2*col - 1, 2 * depth}
+		}
+		if !first && !prevWall && wall {
+			scan.row(depth+1, start, slope{2*col - 1, 2 * depth})
+		}
+		prevWall, first = wall, false
+	}
+	if !first && !prevWall {
+		scan.row(depth+1, start, end)
+	}
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// Line returns the tiles from a to b, both included, along a Bresenham line.
+func Line(a, b GridLocation) []GridLocation {
+	dx, dy := abs(b.X-a.X), -abs(b.Y-a.Y)
+	sx, sy := sign(b.X-a.X), sign(b.Y-a.Y)
+	line := []GridLocation{a}
+	err := dx + dy
+	for a.X != b.X || a.Y != b.Y {
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			a.X += sx
+		}
+		if e2 <= dx {
+			err += dx
+			a.Y += sy
+		}
+		line = append(line, a)
+	}
+	return line
+}
+
+// LineOfSight reports whether nothing opaque lies on the line between a and b.
+func (s *Sight) LineOfSight(a, b GridLocation) bool {
+	line := Line(a, b)
+	for _, loc := range line[1 : len(line)-1] {
+		if s.Opaque(loc) {
+			return false
+		}
+	}
+	return true
+}
+
+// Memory is what a viewer remembers of the map: which tiles it has explored,
+// and what they looked like when it last saw them.
+type Memory struct {
+	width int
+	seen  []bool
+	tiles []Tile
+}
+
+func NewMemory(m *Map) *Memory {
+	return &Memory{
+		width: m.Width(),
+		seen:  make([]bool, m.Width()*m.Height()),
+		tiles: make([]Tile, m.Width()*m.Height()),
+	}
+}
+
+// Update remembers the tiles of m that are visible in v.
+func (mem *Memory) Update(m *Map, v *Visibility) {
+	for loc := range v.All() {
+		i := loc.Y*mem.width + loc.X
+		mem.seen[i] = true
+		mem.tiles[i] = m.Tile(loc)
+	}
+}
+
+func (mem *Memory) Explored(loc GridLocation) bool {
+	_, ok := mem.Remembered(loc)
+	return ok
+}
+
+// Remembered returns the tile at loc as it was last seen.
+func (mem *Memory) Remembered(loc GridLocation) (Tile, bool) {
+	i := loc.Y*mem.width + loc.X
+	if loc.X < 0 || loc.Y < 0 || loc.X >= mem.width || i >= len(mem.seen) || !mem.seen[i] {
+		return 0, false
+	}
+	return mem.tiles[i], true
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func sign(v int) int {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,129 @@
+package game
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/PieterD/boevig/ecs"
+	"github.com/stretchr/testify/require"
+)
+
+// renderVisible draws the map with unseen tiles as spaces.
+func renderVisible(m *Map, v *Visibility) string {
+	var sb strings.Builder
+	for y := 0; y < m.Height(); y++ {
+		for x := 0; x < m.Width(); x++ {
+			loc := GridLocation{X: x, Y: y}
+			if !v.Visible(loc) {
+				sb.WriteByte(' ')
+				continue
+			}
+			sb.WriteByte(m.Tile(loc).Glyph())
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func TestSight_FOV(t *testing.T) {
+	m := testMap(t,
+		"#########",
+		"#.......#",
+		"#...#...#",
+		"#.......#",
+		"#########",
+	)
+	s := NewSight(m)
+	t.Run("pillar", func(t *testing.T) {
+		v := s.FOV(GridLocation{X: 2, Y: 2}, 0)
+		require.Equal(t, ""+
+			"#########\n"+
+			"#......  \n"+
+			"#...#    \n"+
+			"#......  \n"+
+			"#########\n", renderVisible(m, v))
+	})
+	t.Run("radius", func(t *testing.T) {
+		v := s.FOV(GridLocation{X: 2, Y: 2}, 2)
+		require.True(t, v.Visible(GridLocation{X: 4, Y: 2}))
+		require.False(t, v.Visible(GridLocation{X: 5, Y: 1}))
+		require.False(t, v.Visible(GridLocation{X: 7, Y: 2}))
+	})
+	t.Run("entities", func(t *testing.T) {
+		db := ecs.New()
+		db.NewEntity(GridLocation{X: 3, Y: 1}, BlocksSight{})
+		db.NewEntity(GridLocation{X: 3, Y: 3})
+		s := NewSight(m)
+		s.BlockEntities(db)
+		v := s.FOV(GridLocation{X: 2, Y: 1}, 0)
+		require.True(t, v.Visible(GridLocation{X: 3, Y: 1}))
+		require.False(t, v.Visible(GridLocation{X: 4, Y: 1}))
+		require.True(t, v.Visible(GridLocation{X: 4, Y: 3}))
+		s.ClearBlockers()
+		require.True(t, s.FOV(GridLocation{X: 2, Y: 1}, 0).Visible(GridLocation{X: 4, Y: 1}))
+	})
+	t.Run("symmetric", func(t *testing.T) {
+		rnd := rand.New(rand.NewSource(1))
+		m := NewMap(16, 12)
+		for y := 0; y < m.Height(); y++ {
+			for x := 0; x < m.Width(); x++ {
+				if rnd.Intn(5) > 0 {
+					m.SetTile(GridLocation{X: x, Y: y}, Floor)
+				}
+			}
+		}
+		s := NewSight(m)
+		views := make(map[GridLocation]*Visibility)
+		var floors []GridLocation
+		for y := 0; y < m.Height(); y++ {
+			for x := 0; x < m.Width(); x++ {
+				loc := GridLocation{X: x, Y: y}
+				if m.Transparent(loc) {
+					floors = append(floors, loc)
+					views[loc] = s.FOV(loc, 0)
+				}
+			}
+		}
+		for _, a := range floors {
+			for _, b := range floors {
+				require.Equal(t, views[a].Visible(b), views[b].Visible(a), "%v and %v", a, b)
+			}
+		}
+	})
+}
+
+func TestLine(t *testing.T) {
+	require.Equal(t, []GridLocation{{X: 0, Y: 0}}, Line(GridLocation{}, GridLocation{}))
+	require.Equal(t, []GridLocation{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 1}, {X: 3, Y: 1}}, Line(GridLocation{}, GridLocation{X: 3, Y: 1}))
+	require.Equal(t, []GridLocation{{X: 2, Y: 2}, {X: 1, Y: 1}, {X: 0, Y: 0}}, Line(GridLocation{X: 2, Y: 2}, GridLocation{}))
+	m := testMap(t,
+		".....",
+		"..#..",
+		".....",
+	)
+	s := NewSight(m)
+	require.False(t, s.LineOfSight(GridLocation{X: 0, Y: 1}, GridLocation{X: 4, Y: 1}))
+	require.True(t, s.LineOfSight(GridLocation{X: 0, Y: 0}, GridLocation{X: 4, Y: 0}))
+	require.True(t, s.LineOfSight(GridLocation{X: 0, Y: 1}, GridLocation{X: 2, Y: 1}), "the end may be opaque")
+}
+
+func TestMemory(t *testing.T) {
+	m := testMap(t,
+		"#####",
+		"#.#.#",
+		"#####",
+	)
+	s := NewSight(m)
+	mem := NewMemory(m)
+	require.False(t, mem.Explored(GridLocation{X: 1, Y: 1}))
+	mem.Update(m, s.FOV(GridLocation{X: 1, Y: 1}, 0))
+	require.True(t, mem.Explored(GridLocation{X: 1, Y: 1}))
+	require.True(t, mem.Explored(GridLocation{X: 2, Y: 1}))
+	require.False(t, mem.Explored(GridLocation{X: 3, Y: 1}))
+	require.False(t, mem.Explored(GridLocation{X: -1, Y: 1}))
+	m.SetTile(GridLocation{X: 2, Y: 1}, Door)
+	tile, ok := mem.Remembered(GridLocation{X: 2, Y: 1})
+	require.True(t, ok)
+	require.Equal(t, Wall, tile, "memory keeps the tile as it was seen")
+}
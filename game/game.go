@@ -1,14 +1,37 @@
 package game
 
-type Player struct{}
+import "github.com/PieterD/boevig/ecs"
+
+type Player struct {
+	ecs.ComponentHeader[Player, *Player]
+}
+
+// LocationIndex finds the entities on a GridLocation.
+const LocationIndex = "game.GridLocation"
 
 type GridLocation struct {
+	ecs.ComponentHeader[GridLocation, *GridLocation]
 	X int
 	Y int
 }
 
+func (loc GridLocation) Index() []ecs.Indexer {
+	return []ecs.Indexer{
+		ecs.EQ(LocationIndex, loc),
+	}
+}
+
+// Step returns the location one step in direction d, it panics on invalid directions.
+func (loc GridLocation) Step(d Direction) GridLocation {
+	dx, dy := d.Delta()
+	loc.X += dx
+	loc.Y += dy
+	return loc
+}
+
 type Terrain struct {
-	Passable bool
+	Passable    bool
+	Transparent bool
 }
 
 type Direction int
@@ -0,0 +1,81 @@
+package gen
+
+import (
+	"github.com/PieterD/boevig/game"
+	"github.com/PieterD/boevig/game/rng"
+)
+
+// BSP splits the map into leaves, digs a room in every leaf,
+// and connects sibling leaves with corridors. Spawns are the room centers.
+type BSP struct {
+	// MinLeaf is the smallest leaf size, 8 if zero.
+	MinLeaf int
+	// MinRoom is the smallest room size, 3 if zero. It must be at most MinLeaf-2.
+	MinRoom int
+}
+
+func (g BSP) generate(m *game.Map, rnd *rng.Stream) ([]game.GridLocation, error) {
+	if g.MinLeaf == 0 {
+		g.MinLeaf = 8
+	}
+	if g.MinRoom == 0 {
+		g.MinRoom = 3
+	}
+	g.MinRoom = max(1, min(g.MinRoom, g.MinLeaf-2))
+	var rooms []Rect
+	g.split(m, rnd, interior(m), &rooms)
+	var spawns []game.GridLocation
+	for _, room := range rooms {
+		spawns = append(spawns, room.Center())
+	}
+	return spawns, nil
+}
+
+// split digs the rooms of leaf and returns one of them, to connect leaf to its sibling.
+func (g BSP) split(m *game.Map, rnd *rng.Stream, leaf Rect, rooms *[]Rect) Rect {
+	vertical := rnd.IntN(2) == 0
+	switch {
+	case leaf.W*4 > leaf.H*5:
+		vertical = true
+	case leaf.H*4 > leaf.W*5:
+		vertical = false
+	}
+	size := leaf.H
+	if vertical {
+		size = leaf.W
+	}
+	if size < 2*g.MinLeaf {
+		return g.room(m, rnd, leaf, rooms)
+	}
+	at := g.MinLeaf + rnd.IntN(size-2*g.MinLeaf+1)
+	first, second := leaf, leaf
+	if vertical {
+		first.W = at
+		second.X, second.W = leaf.X+at, leaf.W-at
+	} else {
+		first.H = at
+		second.Y, second.H = leaf.Y+at, leaf.H-at
+	}
+	a := g.split(m, rnd, first, rooms)
+	b := g.split(m, rnd, second, rooms)
+	carveCorridor(m, rnd, a.Center(), b.Center())
+	if rnd.IntN(2) == 0 {
+		return a
+	}
+	return b
+}
+
+func (g BSP) room(m *game.Map, rnd *rng.Stream, leaf Rect, rooms *[]Rect) Rect {
+	// Keep a wall between rooms in neighbouring leaves.
+	maxW, maxH := max(1, leaf.W-1), max(1, leaf.H-1)
+	minW, minH := min(g.MinRoom, maxW), min(g.MinRoom, maxH)
+	room := Rect{
+		W: minW + rnd.IntN(maxW-minW+1),
+		H: minH + rnd.IntN(maxH-minH+1),
+	}
+	room.X = leaf.X + rnd.IntN(maxW-room.W+1)
+	room.Y = leaf.Y + rnd.IntN(maxH-room.H+1)
+	carveRect(m, room)
+	*rooms = append(*rooms, room)
+	return room
+}
@@ -0,0 +1,63 @@
+package gen
+
+import (
+	"github.com/PieterD/boevig/game"
+	"github.com/PieterD/boevig/game/rng"
+)
+
+// Caves fills the map with random noise and smooths it with a cellular automaton.
+// Only the largest cave is kept.
+type Caves struct {
+	// Fill is the chance of a tile starting as a wall, 0.45 if zero.
+	Fill float64
+	// Steps is the number of smoothing steps, 4 if zero.
+	Steps int
+	// Spawns is the number of spawns to pick, 10 if zero.
+	Spawns int
+}
+
+func (g Caves) generate(m *game.Map, rnd *rng.Stream) ([]game.GridLocation, error) {
+	if g.Fill == 0 {
+		g.Fill = 0.45
+	}
+	if g.Steps == 0 {
+		g.Steps = 4
+	}
+	if g.Spawns == 0 {
+		g.Spawns = 10
+	}
+	area := interior(m)
+	for y := area.Y; y < area.Y+area.H; y++ {
+		for x := area.X; x < area.X+area.W; x++ {
+			if rnd.Float64() >= g.Fill {
+				m.SetTile(game.GridLocation{X: x, Y: y}, game.Floor)
+			}
+		}
+	}
+	next := make([]game.Tile, area.W*area.H)
+	for step := 0; step < g.Steps; step++ {
+		for y := 0; y < area.H; y++ {
+			for x := 0; x < area.W; x++ {
+				loc := game.GridLocation{X: area.X + x, Y: area.Y + y}
+				walls := 0
+				for _, n := range loc.Neighbours8() {
+					if m.Tile(n) == game.Wall {
+						walls++
+					}
+				}
+				next[y*area.W+x] = game.Floor
+				if walls > 4 || (walls == 4 && m.Tile(loc) == game.Wall) {
+					next[y*area.W+x] = game.Wall
+				}
+			}
+		}
+		for i, tile := range next {
+			m.SetTile(game.GridLocation{X: area.X + i%area.W, Y: area.Y + i/area.W}, tile)
+		}
+	}
+	if len(Regions(m)) == 0 {
+		m.SetTile(area.Center(), game.Floor)
+	}
+	KeepLargestRegion(m)
+	return sampleFloors(m, rnd, g.Spawns), nil
+}
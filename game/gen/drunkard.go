@@ -0,0 +1,43 @@
+package gen
+
+import (
+	"fmt"
+
+	"github.com/PieterD/boevig/game"
+	"github.com/PieterD/boevig/game/rng"
+)
+
+// DrunkardsWalk digs from the center of the map in random steps, until enough of it is floor.
+type DrunkardsWalk struct {
+	// Coverage is the part of the map to dig out, 0.4 if zero. It must be at most 1.
+	Coverage float64
+	// Spawns is the number of spawns to pick, 10 if zero.
+	Spawns int
+}
+
+func (g DrunkardsWalk) generate(m *game.Map, rnd *rng.Stream) ([]game.GridLocation, error) {
+	if g.Coverage < 0 || g.Coverage > 1 {
+		return nil, fmt.Errorf("drunkard's walk coverage %v: must be between 0 and 1", g.Coverage)
+	}
+	if g.Coverage == 0 {
+		g.Coverage = 0.4
+	}
+	if g.Spawns == 0 {
+		g.Spawns = 10
+	}
+	area := interior(m)
+	target := max(1, int(g.Coverage*float64(area.W*area.H)))
+	loc := area.Center()
+	dug := 0
+	for dug < target {
+		if m.Tile(loc) == game.Wall {
+			m.SetTile(loc, game.Floor)
+			dug++
+		}
+		next := loc.Step(game.Directions4[rnd.IntN(len(game.Directions4))])
+		if next.X >= area.X && next.Y >= area.Y && next.X < area.X+area.W && next.Y < area.Y+area.H {
+			loc = next
+		}
+	}
+	return sampleFloors(m, rnd, g.Spawns), nil
+}
@@ -0,0 +1,168 @@
+// Package gen generates dungeon levels. Generators are deterministic:
+// the same generator, size and stream state always produce the same level.
+package gen
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/PieterD/boevig/game"
+	"github.com/PieterD/boevig/game/rng"
+)
+
+type Level struct {
+	Map *game.Map
+	// Spawns are passable locations to place the player, monsters and items on.
+	Spawns []game.GridLocation
+}
+
+type Generator interface {
+	generate(m *game.Map, rnd *rng.Stream) ([]game.GridLocation, error)
+}
+
+var (
+	ErrDisconnected = errors.New("floor is not connected")
+	ErrNoFloor      = errors.New("no passable tiles")
+)
+
+// Generate builds a level of the given size, surrounded by walls, and validates it.
+// It draws from rnd, normally the rng.MapGen stream of the game.
+func Generate(g Generator, width, height int, rnd *rng.Stream) (*Level, error) {
+	if width < 3 || height < 3 {
+		return nil, fmt.Errorf("generating %dx%d level: too small", width, height)
+	}
+	m := game.NewMap(width, height)
+	spawns, err := g.generate(m, rnd)
+	if err != nil {
+		return nil, fmt.Errorf("generating %dx%d level: %w", width, height, err)
+	}
+	level := &Level{
+		Map:    m,
+		Spawns: spawns,
+	}
+	if err := Validate(level); err != nil {
+		return nil, fmt.Errorf("generating %dx%d level: %w", width, height, err)
+	}
+	return level, nil
+}
+
+// Validate checks that there are passable tiles, that every one can be reached from every other,
+// and that the spawns are passable.
+func Validate(level *Level) error {
+	regions := Regions(level.Map)
+	if len(regions) == 0 {
+		return ErrNoFloor
+	}
+	if len(regions) > 1 {
+		return fmt.Errorf("%d separate regions: %w", len(regions), ErrDisconnected)
+	}
+	for _, spawn := range level.Spawns {
+		if err := level.Map.Check(spawn); err != nil {
+			return fmt.Errorf("spawn: %w", err)
+		}
+	}
+	return nil
+}
+
+// Regions returns the 4-connected groups of passable tiles, largest first.
+func Regions(m *game.Map) [][]game.GridLocation {
+	seen := make([]bool, m.Width()*m.Height())
+	var regions [][]game.GridLocation
+	for y := 0; y < m.Height(); y++ {
+		for x := 0; x < m.Width(); x++ {
+			start := game.GridLocation{X: x, Y: y}
+			if seen[y*m.Width()+x] || !m.Passable(start) {
+				continue
+			}
+			seen[y*m.Width()+x] = true
+			region := []game.GridLocation{start}
+			for i := 0; i < len(region); i++ {
+				for _, next := range region[i].Neighbours4() {
+					j := next.Y*m.Width() + next.X
+					if !m.Passable(next) || seen[j] {
+						continue
+					}
+					seen[j] = true
+					region = append(region, next)
+				}
+			}
+			regions = append(regions, region)
+		}
+	}
+	// Equal sized regions stay in scan order, to keep generation deterministic.
+	slices.SortStableFunc(regions, func(a, b []game.GridLocation) int {
+		return len(b) - len(a)
+	})
+	return regions
+}
+
+// KeepLargestRegion fills every region but the largest with walls.
+func KeepLargestRegion(m *game.Map) {
+	regions := Regions(m)
+	if len(regions) <= 1 {
+		return
+	}
+	for _, region := range regions[1:] {
+		for _, loc := range region {
+			m.SetTile(loc, game.Wall)
+		}
+	}
+}
+
+// Rect is an area of the map, X and Y are its top left corner.
+type Rect struct {
+	X, Y, W, H int
+}
+
+func (r Rect) Center() game.GridLocation {
+	return game.GridLocation{X: r.X + r.W/2, Y: r.Y + r.H/2}
+}
+
+// Intersects reports whether r and o overlap or touch.
+func (r Rect) Intersects(o Rect) bool {
+	return r.X <= o.X+o.W && o.X <= r.X+r.W && r.Y <= o.Y+o.H && o.Y <= r.Y+r.H
+}
+
+func carveRect(m *game.Map, r Rect) {
+	for y := r.Y; y < r.Y+r.H; y++ {
+		for x := r.X; x < r.X+r.W; x++ {
+			m.SetTile(game.GridLocation{X: x, Y: y}, game.Floor)
+		}
+	}
+}
+
+// carveCorridor digs an L-shaped corridor from a to b, turning horizontally or vertically first at random.
+func carveCorridor(m *game.Map, rnd *rng.Stream, a, b game.GridLocation) {
+	corner := game.GridLocation{X: b.X, Y: a.Y}
+	if rnd.IntN(2) == 0 {
+		corner = game.GridLocation{X: a.X, Y: b.Y}
+	}
+	for _, leg := range [][2]game.GridLocation{{a, corner}, {corner, b}} {
+		for _, loc := range game.Line(leg[0], leg[1]) {
+			if !m.Passable(loc) {
+				m.SetTile(loc, game.Floor)
+			}
+		}
+	}
+}
+
+// sampleFloors picks up to n distinct passable tiles.
+func sampleFloors(m *game.Map, rnd *rng.Stream, n int) []game.GridLocation {
+	var floors []game.GridLocation
+	for y := 0; y < m.Height(); y++ {
+		for x := 0; x < m.Width(); x++ {
+			if loc := (game.GridLocation{X: x, Y: y}); m.Passable(loc) {
+				floors = append(floors, loc)
+			}
+		}
+	}
+	rnd.Shuffle(len(floors), func(i, j int) {
+		floors[i], floors[j] = floors[j], floors[i]
+	})
+	return floors[:min(n, len(floors))]
+}
+
+func interior(m *game.Map) Rect {
+	return Rect{X: 1, Y: 1, W: m.Width() - 2, H: m.Height() - 2}
+}
@@ -0,0 +1,137 @@
+package gen
+
+import (
+	"testing"
+
+	"github.com/PieterD/boevig/game"
+	"github.com/PieterD/boevig/game/rng"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	generators := map[string]Generator{
+		"bsp":      BSP{},
+		"caves":    Caves{},
+		"drunkard": DrunkardsWalk{},
+		"prefabs": Prefabs{Rooms: [][]string{
+			{
+				"#####",
+				"#...#",
+				"#.~.#",
+				"#...#",
+				"#####",
+			},
+			{
+				"#######",
+				"#.....+",
+				"#######",
+			},
+		}},
+	}
+	for name, g := range generators {
+		t.Run(name, func(t *testing.T) {
+			for seed := uint64(0); seed < 20; seed++ {
+				level, err := Generate(g, 60, 30, stream(seed))
+				require.NoError(t, err, "seed %d", seed)
+				require.NotEmpty(t, level.Spawns, "seed %d", seed)
+				m := level.Map
+				for x := 0; x < m.Width(); x++ {
+					require.Equal(t, game.Wall, m.Tile(game.GridLocation{X: x, Y: 0}))
+					require.Equal(t, game.Wall, m.Tile(game.GridLocation{X: x, Y: m.Height() - 1}))
+				}
+				for y := 0; y < m.Height(); y++ {
+					require.Equal(t, game.Wall, m.Tile(game.GridLocation{X: 0, Y: y}))
+					require.Equal(t, game.Wall, m.Tile(game.GridLocation{X: m.Width() - 1, Y: y}))
+				}
+				again, err := Generate(g, 60, 30, stream(seed))
+				require.NoError(t, err)
+				require.Equal(t, m.String(), again.Map.String(), "seed %d is not deterministic", seed)
+				require.Equal(t, level.Spawns, again.Spawns)
+			}
+			a, err := Generate(g, 60, 30, stream(1))
+			require.NoError(t, err)
+			b, err := Generate(g, 60, 30, stream(2))
+			require.NoError(t, err)
+			require.NotEqual(t, a.Map.String(), b.Map.String())
+		})
+	}
+	t.Run("stream advances", func(t *testing.T) {
+		s := stream(1)
+		a, err := Generate(BSP{}, 60, 30, s)
+		require.NoError(t, err)
+		b, err := Generate(BSP{}, 60, 30, s)
+		require.NoError(t, err)
+		require.NotEqual(t, a.Map.String(), b.Map.String())
+	})
+	t.Run("too small", func(t *testing.T) {
+		_, err := Generate(BSP{}, 2, 10, stream(1))
+		require.Error(t, err)
+	})
+	t.Run("tiny", func(t *testing.T) {
+		for name, g := range generators {
+			_, err := Generate(g, 5, 5, stream(1))
+			if name == "prefabs" {
+				// None of the rooms fit, so nothing is dug.
+				require.ErrorIs(t, err, ErrNoFloor)
+				continue
+			}
+			require.NoError(t, err, name)
+		}
+	})
+	t.Run("invalid prefabs", func(t *testing.T) {
+		_, err := Generate(Prefabs{Rooms: [][]string{{"#x#"}}}, 10, 10, stream(1))
+		require.ErrorContains(t, err, "parsing prefab 0")
+		_, err = Generate(Prefabs{Rooms: [][]string{{"###", "#~#", "###"}}}, 10, 10, stream(1))
+		require.ErrorIs(t, err, ErrNoFloor)
+		require.ErrorContains(t, err, "parsing prefab 0")
+	})
+	t.Run("coverage", func(t *testing.T) {
+		_, err := Generate(DrunkardsWalk{Coverage: 1.5}, 10, 10, stream(1))
+		require.Error(t, err)
+		_, err = Generate(DrunkardsWalk{Coverage: -1}, 10, 10, stream(1))
+		require.Error(t, err)
+		level, err := Generate(DrunkardsWalk{Coverage: 1}, 10, 10, stream(1))
+		require.NoError(t, err)
+		require.Len(t, Regions(level.Map)[0], 64)
+	})
+}
+
+func stream(seed uint64) *rng.Stream {
+	return rng.New(seed).Stream(rng.MapGen)
+}
+
+func TestRegions(t *testing.T) {
+	m, err := game.ParseMap(
+		"#######",
+		"#..#..#",
+		"#..#..#",
+		"####.##",
+		"#.~####",
+		"#######",
+	)
+	require.NoError(t, err)
+	regions := Regions(m)
+	require.Len(t, regions, 3)
+	require.Len(t, regions[0], 5)
+	require.Len(t, regions[1], 4)
+	require.Len(t, regions[2], 1)
+	err = Validate(&Level{Map: m})
+	require.ErrorIs(t, err, ErrDisconnected)
+	require.ErrorIs(t, Validate(&Level{Map: game.NewMap(3, 3)}), ErrNoFloor)
+	KeepLargestRegion(m)
+	require.Equal(t, ""+
+		"#######\n"+
+		"####..#\n"+
+		"####..#\n"+
+		"####.##\n"+
+		"##~####\n"+
+		"#######\n", m.String())
+	require.NoError(t, Validate(&Level{Map: m}))
+	err = Validate(&Level{Map: m, Spawns: []game.GridLocation{{X: 1, Y: 1}}})
+	require.ErrorIs(t, err, game.ErrImpassable)
+
+	walls := game.NewMap(5, 5)
+	require.Empty(t, Regions(walls))
+	KeepLargestRegion(walls)
+	require.Equal(t, game.NewMap(5, 5).String(), walls.String())
+}
@@ -0,0 +1,112 @@
+package gen
+
+import (
+	"fmt"
+
+	"github.com/PieterD/boevig/game"
+	"github.com/PieterD/boevig/game/rng"
+)
+
+// Prefabs places hand made rooms at random and stitches them together with corridors.
+// Rooms are written in the glyphs of game.ParseMap, spawns are their first floor tiles.
+type Prefabs struct {
+	Rooms [][]string
+	// Count is the number of rooms to try to place, 8 if zero.
+	Count int
+	// Attempts is the number of tries to place each room without overlap, 20 if zero.
+	Attempts int
+}
+
+// prefab is a parsed room, anchor is its first floor tile.
+type prefab struct {
+	*game.Map
+	anchor game.GridLocation
+}
+
+func (g Prefabs) generate(m *game.Map, rnd *rng.Stream) ([]game.GridLocation, error) {
+	if g.Count == 0 {
+		g.Count = 8
+	}
+	if g.Attempts == 0 {
+		g.Attempts = 20
+	}
+	prefabs, err := g.parse()
+	if err != nil {
+		return nil, err
+	}
+	area := interior(m)
+	var placed []Rect
+	var anchors []game.GridLocation
+	for i := 0; i < g.Count && len(prefabs) > 0; i++ {
+		prefab := prefabs[rnd.IntN(len(prefabs))]
+		if prefab.Width() > area.W || prefab.Height() > area.H {
+			continue
+		}
+		for attempt := 0; attempt < g.Attempts; attempt++ {
+			r := Rect{
+				X: area.X + rnd.IntN(area.W-prefab.Width()+1),
+				Y: area.Y + rnd.IntN(area.H-prefab.Height()+1),
+				W: prefab.Width(),
+				H: prefab.Height(),
+			}
+			if overlaps(r, placed) {
+				continue
+			}
+			stamp(m, prefab.Map, r)
+			placed = append(placed, r)
+			anchors = append(anchors, game.GridLocation{X: r.X + prefab.anchor.X, Y: r.Y + prefab.anchor.Y})
+			break
+		}
+	}
+	// Corridors are dug after all rooms are placed, so no room is stamped over them.
+	for i := 1; i < len(anchors); i++ {
+		carveCorridor(m, rnd, anchors[i-1], anchors[i])
+	}
+	return anchors, nil
+}
+
+// parse reads the rooms, every one needs a floor tile to connect it by.
+func (g Prefabs) parse() ([]prefab, error) {
+	var prefabs []prefab
+	for i, rows := range g.Rooms {
+		m, err := game.ParseMap(rows...)
+		if err != nil {
+			return nil, fmt.Errorf("parsing prefab %d: %w", i, err)
+		}
+		anchor, ok := firstFloor(m)
+		if !ok {
+			return nil, fmt.Errorf("parsing prefab %d: %w", i, ErrNoFloor)
+		}
+		prefabs = append(prefabs, prefab{Map: m, anchor: anchor})
+	}
+	return prefabs, nil
+}
+
+func overlaps(r Rect, placed []Rect) bool {
+	for _, p := range placed {
+		if r.Intersects(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// stamp copies prefab onto m at r.
+func stamp(m *game.Map, prefab *game.Map, r Rect) {
+	for y := 0; y < r.H; y++ {
+		for x := 0; x < r.W; x++ {
+			m.SetTile(game.GridLocation{X: r.X + x, Y: r.Y + y}, prefab.Tile(game.GridLocation{X: x, Y: y}))
+		}
+	}
+}
+
+func firstFloor(m *game.Map) (game.GridLocation, bool) {
+	for y := 0; y < m.Height(); y++ {
+		for x := 0; x < m.Width(); x++ {
+			if loc := (game.GridLocation{X: x, Y: y}); m.Tile(loc) == game.Floor {
+				return loc, true
+			}
+		}
+	}
+	return game.GridLocation{}, false
+}
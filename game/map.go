@@ -0,0 +1,196 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+
+	"github.com/PieterD/boevig/ecs"
+)
+
+// Tile is the kind of terrain on a map tile. The zero Tile is a wall,
+// so a new map is solid rock.
+type Tile uint8
+
+const (
+	Wall Tile = iota
+	Floor
+	Door
+	Water
+)
+
+var tiles = []struct {
+	terrain Terrain
+	glyph   byte
+	name    string
+}{
+	Wall:  {terrain: Terrain{Passable: false, Transparent: false}, glyph: '#', name: "wall"},
+	Floor: {terrain: Terrain{Passable: true, Transparent: true}, glyph: '.', name: "floor"},
+	Door:  {terrain: Terrain{Passable: true, Transparent: false}, glyph: '+', name: "door"},
+	Water: {terrain: Terrain{Passable: false, Transparent: true}, glyph: '~', name: "water"},
+}
+
+// Terrain returns the terrain of t, unknown tiles are impassable and opaque.
+func (t Tile) Terrain() Terrain {
+	if int(t) >= len(tiles) {
+		return Terrain{}
+	}
+	return tiles[t].terrain
+}
+
+// Glyph returns the map glyph of t, unknown tiles are '?'.
+func (t Tile) Glyph() byte {
+	if int(t) >= len(tiles) {
+		return '?'
+	}
+	return tiles[t].glyph
+}
+
+func (t Tile) String() string {
+	if int(t) >= len(tiles) {
+		return fmt.Sprintf("Tile(%d)", t)
+	}
+	return tiles[t].name
+}
+
+var (
+	ErrOutOfBounds = errors.New("out of bounds")
+	ErrImpassable  = errors.New("impassable")
+)
+
+// Map is a rectangular grid of tiles, stored a byte per tile.
+type Map struct {
+	width  int
+	height int
+	tiles  []Tile
+}
+
+func NewMap(width, height int) *Map {
+	if width < 0 || height < 0 {
+		panic(fmt.Errorf("creating %dx%d map: negative size", width, height))
+	}
+	return &Map{
+		width:  width,
+		height: height,
+		tiles:  make([]Tile, width*height),
+	}
+}
+
+// ParseMap reads a map from rows of glyphs: # wall, . floor, + door, ~ water.
+func ParseMap(rows ...string) (*Map, error) {
+	width := 0
+	if len(rows) > 0 {
+		width = len(rows[0])
+	}
+	m := NewMap(width, len(rows))
+	for y, row := range rows {
+		if len(row) != width {
+			return nil, fmt.Errorf("parsing map row %d: got width %d, want %d", y, len(row), width)
+		}
+		for x := 0; x < width; x++ {
+			tile, ok := glyphTile(row[x])
+			if !ok {
+				return nil, fmt.Errorf("parsing map at %d,%d: unknown glyph %q", x, y, row[x])
+			}
+			m.tiles[y*width+x] = tile
+		}
+	}
+	return m, nil
+}
+
+func glyphTile(glyph byte) (Tile, bool) {
+	for tile, info := range tiles {
+		if info.glyph == glyph {
+			return Tile(tile), true
+		}
+	}
+	return 0, false
+}
+
+func (m *Map) Width() int {
+	return m.width
+}
+
+func (m *Map) Height() int {
+	return m.height
+}
+
+func (m *Map) InBounds(loc GridLocation) bool {
+	return loc.X >= 0 && loc.Y >= 0 && loc.X < m.width && loc.Y < m.height
+}
+
+// Tile returns the tile at loc, tiles out of bounds are walls.
+func (m *Map) Tile(loc GridLocation) Tile {
+	if !m.InBounds(loc) {
+		return Wall
+	}
+	return m.tiles[loc.Y*m.width+loc.X]
+}
+
+func (m *Map) SetTile(loc GridLocation, tile Tile) {
+	if !m.InBounds(loc) {
+		panic(fmt.Errorf("setting tile at %d,%d: %w", loc.X, loc.Y, ErrOutOfBounds))
+	}
+	m.tiles[loc.Y*m.width+loc.X] = tile
+}
+
+func (m *Map) Terrain(loc GridLocation) Terrain {
+	return m.Tile(loc).Terrain()
+}
+
+func (m *Map) Passable(loc GridLocation) bool {
+	return m.Terrain(loc).Passable
+}
+
+func (m *Map) Transparent(loc GridLocation) bool {
+	return m.Terrain(loc).Transparent
+}
+
+// Neighbours yields the locations around loc that are in bounds, clockwise from north.
+func (m *Map) Neighbours(loc GridLocation) iter.Seq2[Direction, GridLocation] {
+	return func(yield func(Direction, GridLocation) bool) {
+		for d, next := range loc.Neighbours8() {
+			if !m.InBounds(next) {
+				continue
+			}
+			if !yield(d, next) {
+				return
+			}
+		}
+	}
+}
+
+// Check returns ErrOutOfBounds or ErrImpassable if nothing can stand on loc.
+func (m *Map) Check(loc GridLocation) error {
+	if !m.InBounds(loc) {
+		return fmt.Errorf("location %d,%d: %w", loc.X, loc.Y, ErrOutOfBounds)
+	}
+	if !m.Passable(loc) {
+		return fmt.Errorf("location %d,%d on %v: %w", loc.X, loc.Y, m.Tile(loc), ErrImpassable)
+	}
+	return nil
+}
+
+// Validate checks the GridLocation of every entity in db against the map.
+func (m *Map) Validate(db *ecs.DB) error {
+	var errs []error
+	var loc GridLocation
+	for id := range db.Search().Components(&loc).Done() {
+		if err := m.Check(loc); err != nil {
+			errs = append(errs, fmt.Errorf("entity %v: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *Map) String() string {
+	var sb strings.Builder
+	for y := 0; y < m.height; y++ {
+		for x := 0; x < m.width; x++ {
+			sb.WriteByte(m.tiles[y*m.width+x].Glyph())
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -0,0 +1,100 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/PieterD/boevig/ecs"
+	"github.com/stretchr/testify/require"
+)
+
+func testMap(t *testing.T, rows ...string) *Map {
+	t.Helper()
+	m, err := ParseMap(rows...)
+	require.NoError(t, err)
+	return m
+}
+
+func TestMap(t *testing.T) {
+	t.Run("new map is walls", func(t *testing.T) {
+		m := NewMap(3, 2)
+		require.Equal(t, 3, m.Width())
+		require.Equal(t, 2, m.Height())
+		require.Equal(t, "###\n###\n", m.String())
+		require.False(t, m.Passable(GridLocation{X: 1, Y: 1}))
+	})
+	t.Run("parse and print", func(t *testing.T) {
+		m := testMap(t,
+			"#####",
+			"#..~#",
+			"#+###",
+		)
+		require.Equal(t, "#####\n#..~#\n#+###\n", m.String())
+		require.Equal(t, Floor, m.Tile(GridLocation{X: 1, Y: 1}))
+		require.Equal(t, Water, m.Tile(GridLocation{X: 3, Y: 1}))
+		require.Equal(t, Door, m.Tile(GridLocation{X: 1, Y: 2}))
+		require.True(t, m.Passable(GridLocation{X: 1, Y: 2}))
+		require.False(t, m.Passable(GridLocation{X: 3, Y: 1}))
+	})
+	t.Run("parse errors", func(t *testing.T) {
+		_, err := ParseMap("##", "#")
+		require.Error(t, err)
+		_, err = ParseMap("#x")
+		require.Error(t, err)
+	})
+	t.Run("bounds", func(t *testing.T) {
+		m := NewMap(2, 2)
+		require.True(t, m.InBounds(GridLocation{X: 1, Y: 1}))
+		require.False(t, m.InBounds(GridLocation{X: 2, Y: 1}))
+		require.False(t, m.InBounds(GridLocation{X: 0, Y: -1}))
+		require.Equal(t, Wall, m.Tile(GridLocation{X: -1, Y: 0}))
+		m.SetTile(GridLocation{X: 1, Y: 0}, Floor)
+		require.Equal(t, "#.\n##\n", m.String())
+		require.Panics(t, func() { m.SetTile(GridLocation{X: 2, Y: 0}, Floor) })
+	})
+	t.Run("unknown tile", func(t *testing.T) {
+		m := NewMap(2, 1)
+		unknown := GridLocation{X: 1, Y: 0}
+		m.SetTile(unknown, Tile(200))
+		require.Equal(t, Terrain{}, m.Terrain(unknown))
+		require.False(t, m.Passable(unknown))
+		require.False(t, m.Transparent(unknown))
+		require.Equal(t, "Tile(200)", m.Tile(unknown).String())
+		require.Equal(t, "#?\n", m.String())
+		require.ErrorIs(t, m.Check(unknown), ErrImpassable)
+	})
+	t.Run("neighbours", func(t *testing.T) {
+		m := NewMap(3, 3)
+		collect := func(loc GridLocation) map[Direction]GridLocation {
+			got := make(map[Direction]GridLocation)
+			for d, next := range m.Neighbours(loc) {
+				got[d] = next
+			}
+			return got
+		}
+		require.Len(t, collect(GridLocation{X: 1, Y: 1}), 8)
+		require.Equal(t, map[Direction]GridLocation{
+			E:  {X: 1, Y: 0},
+			SE: {X: 1, Y: 1},
+			S:  {X: 0, Y: 1},
+		}, collect(GridLocation{X: 0, Y: 0}))
+	})
+}
+
+func TestMap_Validate(t *testing.T) {
+	m := testMap(t,
+		"###",
+		"#.#",
+		"###",
+	)
+	db := ecs.New()
+	db.NewEntity(GridLocation{X: 1, Y: 1})
+	require.NoError(t, m.Validate(db))
+	wall := db.NewEntity(GridLocation{X: 0, Y: 1})
+	outside := db.NewEntity(GridLocation{X: 5, Y: 5})
+	err := m.Validate(db)
+	require.ErrorIs(t, err, ErrImpassable)
+	require.ErrorIs(t, err, ErrOutOfBounds)
+	db.Remove(wall)
+	db.Remove(outside)
+	require.NoError(t, m.Validate(db))
+}
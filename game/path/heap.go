@@ -0,0 +1,64 @@
+package path
+
+// openHeap is a binary min-heap of search nodes, ordered by estimated total cost.
+// Ties go to the node with the highest cost so far, which is closest to the goal.
+type openHeap struct {
+	nodes []openNode
+}
+
+type openNode struct {
+	index    int32
+	estimate float64
+	cost     float64
+}
+
+func (h *openHeap) len() int {
+	return len(h.nodes)
+}
+
+func (h *openHeap) clear() {
+	h.nodes = h.nodes[:0]
+}
+
+func (h *openHeap) less(i, j int) bool {
+	a, b := h.nodes[i], h.nodes[j]
+	if a.estimate != b.estimate {
+		return a.estimate < b.estimate
+	}
+	return a.cost > b.cost
+}
+
+func (h *openHeap) push(index int, estimate, cost float64) {
+	h.nodes = append(h.nodes, openNode{index: int32(index), estimate: estimate, cost: cost})
+	i := len(h.nodes) - 1
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.less(i, parent) {
+			break
+		}
+		h.nodes[i], h.nodes[parent] = h.nodes[parent], h.nodes[i]
+		i = parent
+	}
+}
+
+func (h *openHeap) pop() openNode {
+	top := h.nodes[0]
+	last := len(h.nodes) - 1
+	h.nodes[0] = h.nodes[last]
+	h.nodes = h.nodes[:last]
+	i := 0
+	for {
+		smallest := i
+		if left := 2*i + 1; left < len(h.nodes) && h.less(left, smallest) {
+			smallest = left
+		}
+		if right := 2*i + 2; right < len(h.nodes) && h.less(right, smallest) {
+			smallest = right
+		}
+		if smallest == i {
+			return top
+		}
+		h.nodes[i], h.nodes[smallest] = h.nodes[smallest], h.nodes[i]
+		i = smallest
+	}
+}
@@ -0,0 +1,217 @@
+// Package path finds paths over game maps.
+package path
+
+import (
+	"math"
+
+	"github.com/PieterD/boevig/ecs"
+	"github.com/PieterD/boevig/game"
+)
+
+type Options struct {
+	// Diagonal allows diagonal steps, making the map 8-connected.
+	Diagonal bool
+	// DiagonalCost is the cost of a diagonal step, orthogonal steps cost 1. Zero means 1.
+	DiagonalCost float64
+	// CutCorners allows diagonal steps past impassable terrain on either side.
+	CutCorners bool
+	// MaxCost stops the search at paths costing more. Zero means no limit.
+	MaxCost float64
+}
+
+// Finder searches paths on a map. It keeps its buffers between searches,
+// so a single Finder can path for every monster, every turn. It is not safe for concurrent use.
+type Finder struct {
+	m       *game.Map
+	opts    Options
+	blocked []bool
+	// Per tile search state, valid only when its stamp equals gen.
+	gen    uint32
+	stamp  []uint32
+	closed []uint32
+	cost   []float64
+	parent []int32
+	open   openHeap
+}
+
+func NewFinder(m *game.Map, opts Options) *Finder {
+	if opts.DiagonalCost == 0 {
+		opts.DiagonalCost = 1
+	}
+	n := m.Width() * m.Height()
+	return &Finder{
+		m:       m,
+		opts:    opts,
+		blocked: make([]bool, n),
+		stamp:   make([]uint32, n),
+		closed:  make([]uint32, n),
+		cost:    make([]float64, n),
+		parent:  make([]int32, n),
+	}
+}
+
+// Block marks loc as occupied, on top of the map's terrain.
+func (f *Finder) Block(loc game.GridLocation) {
+	if f.m.InBounds(loc) {
+		f.blocked[f.index(loc)] = true
+	}
+}
+
+// BlockEntities blocks the location of every entity in db
+// that has a GridLocation and all of the given components.
+func (f *Finder) BlockEntities(db *ecs.DB, componentPtrs ...ecs.Component) {
+	var loc game.GridLocation
+	for range db.Search().Components(append([]ecs.Component{&loc}, componentPtrs...)...).Done() {
+		f.Block(loc)
+	}
+}
+
+func (f *Finder) ClearBlockers() {
+	clear(f.blocked)
+}
+
+// AStar returns the path from from to to, excluding from and including to.
+// The goal itself may be blocked, so monsters can path to whoever stands there.
+func (f *Finder) AStar(from, to game.GridLocation) ([]game.GridLocation, bool) {
+	if !f.m.InBounds(to) || !f.m.Passable(to) {
+		return nil, false
+	}
+	goal := f.index(to)
+	return f.search(from, func(i int) bool {
+		return i == goal
+	}, func(loc game.GridLocation) float64 {
+		return f.heuristic(loc, to)
+	})
+}
+
+// Dijkstra returns the path from from to the cheapest location for which goal returns true,
+// excluding from and including the goal. Goal locations may be blocked.
+func (f *Finder) Dijkstra(from game.GridLocation, goal func(game.GridLocation) bool) ([]game.GridLocation, bool) {
+	return f.search(from, func(i int) bool {
+		return goal(f.location(i))
+	}, func(game.GridLocation) float64 {
+		return 0
+	})
+}
+
+func (f *Finder) search(from game.GridLocation, isGoal func(i int) bool, heuristic func(game.GridLocation) float64) ([]game.GridLocation, bool) {
+	if !f.m.InBounds(from) {
+		return nil, false
+	}
+	f.reset()
+	start := f.index(from)
+	f.visit(start, 0, -1)
+	f.open.push(start, heuristic(from), 0)
+	for f.open.len() > 0 {
+		node := f.open.pop()
+		if f.closed[node.index] == f.gen {
+			continue
+		}
+		f.closed[node.index] = f.gen
+		if isGoal(int(node.index)) {
+			return f.trace(int(node.index)), true
+		}
+		loc := f.location(int(node.index))
+		for _, d := range f.directions() {
+			if !f.passable(loc, d) {
+				continue
+			}
+			next := loc.Step(d)
+			i := f.index(next)
+			if f.closed[i] == f.gen {
+				continue
+			}
+			if f.blocked[i] && !isGoal(i) {
+				continue
+			}
+			cost := node.cost + f.stepCost(d)
+			if f.opts.MaxCost > 0 && cost > f.opts.MaxCost {
+				continue
+			}
+			if f.stamp[i] == f.gen && f.cost[i] <= cost {
+				continue
+			}
+			f.visit(i, cost, int32(node.index))
+			f.open.push(i, cost+heuristic(next), cost)
+		}
+	}
+	return nil, false
+}
+
+// reset invalidates the previous search by moving to a new generation.
+func (f *Finder) reset() {
+	f.gen++
+	if f.gen == 0 {
+		clear(f.stamp)
+		clear(f.closed)
+		f.gen = 1
+	}
+	f.open.clear()
+}
+
+func (f *Finder) visit(i int, cost float64, parent int32) {
+	f.stamp[i] = f.gen
+	f.cost[i] = cost
+	f.parent[i] = parent
+}
+
+func (f *Finder) trace(i int) []game.GridLocation {
+	n := 0
+	for j := i; f.parent[j] >= 0; j = int(f.parent[j]) {
+		n++
+	}
+	path := make([]game.GridLocation, n)
+	for j := i; f.parent[j] >= 0; j = int(f.parent[j]) {
+		n--
+		path[n] = f.location(j)
+	}
+	return path
+}
+
+// passable reports whether a step from loc in direction d is allowed by the terrain.
+func (f *Finder) passable(loc game.GridLocation, d game.Direction) bool {
+	if !f.m.Passable(loc.Step(d)) {
+		return false
+	}
+	return !diagonal(d) || f.opts.CutCorners || f.cornerFree(loc, d)
+}
+
+func (f *Finder) directions() []game.Direction {
+	if f.opts.Diagonal {
+		return game.Directions8
+	}
+	return game.Directions4
+}
+
+func (f *Finder) cornerFree(loc game.GridLocation, d game.Direction) bool {
+	return f.m.Passable(loc.Step(d&(game.N|game.S))) && f.m.Passable(loc.Step(d&(game.E|game.W)))
+}
+
+func (f *Finder) stepCost(d game.Direction) float64 {
+	if diagonal(d) {
+		return f.opts.DiagonalCost
+	}
+	return 1
+}
+
+// heuristic is the octile distance, or the manhattan distance on 4-connected maps.
+func (f *Finder) heuristic(a, b game.GridLocation) float64 {
+	dx := math.Abs(float64(a.X - b.X))
+	dy := math.Abs(float64(a.Y - b.Y))
+	if !f.opts.Diagonal {
+		return dx + dy
+	}
+	return dx + dy + (min(f.opts.DiagonalCost, 2)-2)*min(dx, dy)
+}
+
+func diagonal(d game.Direction) bool {
+	return d&(game.N|game.S) != 0 && d&(game.E|game.W) != 0
+}
+
+func (f *Finder) index(loc game.GridLocation) int {
+	return loc.Y*f.m.Width() + loc.X
+}
+
+func (f *Finder) location(i int) game.GridLocation {
+	return game.GridLocation{X: i % f.m.Width(), Y: i / f.m.Width()}
+}
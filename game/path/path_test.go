@@ -0,0 +1,195 @@
+package path
+
+import (
+	"testing"
+
+	"github.com/PieterD/boevig/ecs"
+	"github.com/PieterD/boevig/game"
+	"github.com/stretchr/testify/require"
+)
+
+func testMap(t testing.TB, rows ...string) *game.Map {
+	t.Helper()
+	m, err := game.ParseMap(rows...)
+	require.NoError(t, err)
+	return m
+}
+
+func loc(x, y int) game.GridLocation {
+	return game.GridLocation{X: x, Y: y}
+}
+
+func pathCost(opts Options, from game.GridLocation, path []game.GridLocation) float64 {
+	cost := 0.0
+	for _, next := range path {
+		if next.X != from.X && next.Y != from.Y {
+			cost += opts.DiagonalCost
+		} else {
+			cost++
+		}
+		from = next
+	}
+	return cost
+}
+
+func TestFinder_AStar(t *testing.T) {
+	m := testMap(t,
+		"#######",
+		"#.....#",
+		"#.###.#",
+		"#.#...#",
+		"#######",
+	)
+	t.Run("4-connected", func(t *testing.T) {
+		f := NewFinder(m, Options{})
+		path, ok := f.AStar(loc(1, 3), loc(3, 3))
+		require.True(t, ok)
+		require.Len(t, path, 10)
+		require.Equal(t, loc(3, 3), path[len(path)-1])
+		from := loc(1, 3)
+		for _, next := range path {
+			require.True(t, m.Passable(next))
+			require.Equal(t, 1, abs(next.X-from.X)+abs(next.Y-from.Y))
+			from = next
+		}
+	})
+	t.Run("8-connected", func(t *testing.T) {
+		f := NewFinder(m, Options{Diagonal: true, CutCorners: true})
+		path, ok := f.AStar(loc(1, 3), loc(3, 3))
+		require.True(t, ok)
+		require.Equal(t, []game.GridLocation{loc(1, 2), loc(2, 1), loc(3, 1), loc(4, 1), loc(5, 2), loc(4, 3), loc(3, 3)}, path)
+	})
+	t.Run("corners", func(t *testing.T) {
+		f := NewFinder(m, Options{Diagonal: true})
+		path, ok := f.AStar(loc(1, 3), loc(3, 3))
+		require.True(t, ok)
+		require.Len(t, path, 10, "every diagonal step passes a wall")
+	})
+	t.Run("diagonal cost", func(t *testing.T) {
+		open := testMap(t,
+			".....",
+			".....",
+			".....",
+		)
+		opts := Options{Diagonal: true, DiagonalCost: 1.5}
+		f := NewFinder(open, opts)
+		path, ok := f.AStar(loc(0, 0), loc(4, 2))
+		require.True(t, ok)
+		require.Equal(t, 5.0, pathCost(opts, loc(0, 0), path))
+		opts.DiagonalCost = 3
+		f = NewFinder(open, opts)
+		path, ok = f.AStar(loc(0, 0), loc(4, 2))
+		require.True(t, ok)
+		require.Equal(t, 6.0, pathCost(opts, loc(0, 0), path))
+	})
+	t.Run("unreachable", func(t *testing.T) {
+		f := NewFinder(m, Options{})
+		_, ok := f.AStar(loc(1, 1), loc(0, 0))
+		require.False(t, ok)
+		_, ok = f.AStar(loc(1, 1), loc(9, 9))
+		require.False(t, ok)
+	})
+	t.Run("same location", func(t *testing.T) {
+		f := NewFinder(m, Options{})
+		path, ok := f.AStar(loc(1, 1), loc(1, 1))
+		require.True(t, ok)
+		require.Empty(t, path)
+	})
+	t.Run("max cost", func(t *testing.T) {
+		f := NewFinder(m, Options{MaxCost: 9})
+		_, ok := f.AStar(loc(1, 3), loc(3, 3))
+		require.False(t, ok)
+		path, ok := f.AStar(loc(1, 3), loc(5, 1))
+		require.True(t, ok)
+		require.Len(t, path, 6)
+	})
+	t.Run("reuse", func(t *testing.T) {
+		f := NewFinder(m, Options{})
+		for i := 0; i < 3; i++ {
+			path, ok := f.AStar(loc(1, 3), loc(3, 3))
+			require.True(t, ok)
+			require.Len(t, path, 10)
+			_, ok = f.AStar(loc(1, 1), loc(0, 0))
+			require.False(t, ok)
+		}
+	})
+}
+
+func TestFinder_Blockers(t *testing.T) {
+	m := testMap(t,
+		"#####",
+		"#...#",
+		"#.#.#",
+		"#...#",
+		"#####",
+	)
+	type Monster struct {
+		ecs.ComponentHeader[Monster, *Monster]
+	}
+	db := ecs.New()
+	db.NewEntity(game.GridLocation{X: 2, Y: 1}, Monster{})
+	db.NewEntity(game.GridLocation{X: 2, Y: 3})
+	f := NewFinder(m, Options{})
+	var monster Monster
+	f.BlockEntities(db, &monster)
+	path, ok := f.AStar(loc(1, 1), loc(3, 1))
+	require.True(t, ok)
+	require.Len(t, path, 6)
+	path, ok = f.AStar(loc(1, 1), loc(2, 1))
+	require.True(t, ok, "the goal may be blocked")
+	require.Equal(t, []game.GridLocation{loc(2, 1)}, path)
+	f.Block(loc(2, 3))
+	_, ok = f.AStar(loc(1, 1), loc(3, 1))
+	require.False(t, ok)
+	f.ClearBlockers()
+	path, ok = f.AStar(loc(1, 1), loc(3, 1))
+	require.True(t, ok)
+	require.Len(t, path, 2)
+}
+
+func TestFinder_Dijkstra(t *testing.T) {
+	m := testMap(t,
+		"#######",
+		"#.....#",
+		"#.###.#",
+		"#.....#",
+		"#######",
+	)
+	f := NewFinder(m, Options{})
+	targets := map[game.GridLocation]bool{loc(5, 3): true, loc(1, 3): true}
+	path, ok := f.Dijkstra(loc(3, 1), func(l game.GridLocation) bool { return targets[l] })
+	require.True(t, ok)
+	require.Len(t, path, 4)
+	require.True(t, targets[path[len(path)-1]])
+	_, ok = f.Dijkstra(loc(3, 1), func(game.GridLocation) bool { return false })
+	require.False(t, ok)
+}
+
+func BenchmarkFinder_AStar(b *testing.B) {
+	rows := make([]string, 64)
+	for y := range rows {
+		row := make([]byte, 64)
+		for x := range row {
+			row[x] = '.'
+			if x%8 == 4 && y%16 != x%16 {
+				row[x] = '#'
+			}
+		}
+		rows[y] = string(row)
+	}
+	m := testMap(b, rows...)
+	f := NewFinder(m, Options{Diagonal: true, DiagonalCost: 1.4})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, ok := f.AStar(loc(0, 0), loc(63, 63)); !ok {
+			b.Fatal("no path")
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
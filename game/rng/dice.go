@@ -0,0 +1,101 @@
+package rng
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dice is a roll of Count dice with Sides sides each, plus Mod.
+// Without a positive Count and Sides there are no dice to roll, and it always rolls Mod.
+type Dice struct {
+	Count int
+	Sides int
+	Mod   int
+}
+
+// ParseDice parses dice notation such as "3d6+2", "d20" or "2d4-1".
+func ParseDice(s string) (Dice, error) {
+	count, rest, ok := strings.Cut(strings.ToLower(strings.TrimSpace(s)), "d")
+	if !ok {
+		return Dice{}, fmt.Errorf("parsing dice %q: missing d", s)
+	}
+	d := Dice{Count: 1}
+	if count != "" {
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 1 {
+			return Dice{}, fmt.Errorf("parsing dice %q: invalid count %q", s, count)
+		}
+		d.Count = n
+	}
+	sides := rest
+	if i := strings.IndexAny(rest, "+-"); i >= 0 {
+		sides = rest[:i]
+		mod, err := strconv.Atoi(rest[i:])
+		if err != nil {
+			return Dice{}, fmt.Errorf("parsing dice %q: invalid modifier %q", s, rest[i:])
+		}
+		d.Mod = mod
+	}
+	n, err := strconv.Atoi(sides)
+	if err != nil || n < 1 {
+		return Dice{}, fmt.Errorf("parsing dice %q: invalid sides %q", s, sides)
+	}
+	d.Sides = n
+	return d, nil
+}
+
+func MustParseDice(s string) Dice {
+	d, err := ParseDice(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d Dice) Roll(s *Stream) int {
+	total := d.Mod
+	for range d.dice() {
+		total += 1 + s.IntN(d.Sides)
+	}
+	return total
+}
+
+func (d Dice) Min() int {
+	return d.dice() + d.Mod
+}
+
+func (d Dice) Max() int {
+	return d.dice()*d.Sides + d.Mod
+}
+
+// dice is the number of dice that are actually rolled.
+func (d Dice) dice() int {
+	if d.Sides <= 0 {
+		return 0
+	}
+	return max(d.Count, 0)
+}
+
+func (d Dice) String() string {
+	switch {
+	case d.Mod > 0:
+		return fmt.Sprintf("%dd%d+%d", d.Count, d.Sides, d.Mod)
+	case d.Mod < 0:
+		return fmt.Sprintf("%dd%d%d", d.Count, d.Sides, d.Mod)
+	}
+	return fmt.Sprintf("%dd%d", d.Count, d.Sides)
+}
+
+func (d Dice) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Dice) UnmarshalText(text []byte) error {
+	parsed, err := ParseDice(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
@@ -0,0 +1,55 @@
+package rng
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDice(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want Dice
+		str  string
+	}{
+		{in: "3d6+2", want: Dice{Count: 3, Sides: 6, Mod: 2}, str: "3d6+2"},
+		{in: "d20", want: Dice{Count: 1, Sides: 20}, str: "1d20"},
+		{in: " 2D4-1 ", want: Dice{Count: 2, Sides: 4, Mod: -1}, str: "2d4-1"},
+	} {
+		t.Run(test.in, func(t *testing.T) {
+			d, err := ParseDice(test.in)
+			require.NoError(t, err)
+			require.Equal(t, test.want, d)
+			require.Equal(t, test.str, d.String())
+			var text Dice
+			require.NoError(t, text.UnmarshalText([]byte(d.String())))
+			require.Equal(t, d, text)
+		})
+	}
+	for _, in := range []string{"", "6", "0d6", "xd6", "3d", "3d0", "d0", "3d-2", "3d6+", "3d6+x", "3d6*2"} {
+		_, err := ParseDice(in)
+		require.Error(t, err, in)
+	}
+	require.Panics(t, func() { MustParseDice("nope") })
+}
+
+func TestDice_Roll(t *testing.T) {
+	d := MustParseDice("3d6+2")
+	require.Equal(t, 5, d.Min())
+	require.Equal(t, 20, d.Max())
+	s := New(9).Stream(Combat)
+	seen := make(map[int]bool)
+	for range 2000 {
+		n := d.Roll(s)
+		require.GreaterOrEqual(t, n, d.Min())
+		require.LessOrEqual(t, n, d.Max())
+		seen[n] = true
+	}
+	require.Len(t, seen, 16)
+
+	for _, d := range []Dice{{}, {Count: 1}, {Count: 2, Sides: -3, Mod: 4}, {Count: -1, Sides: 6, Mod: 4}} {
+		require.Equal(t, d.Mod, d.Roll(s), "%+v", d)
+		require.Equal(t, d.Mod, d.Min(), "%+v", d)
+		require.Equal(t, d.Mod, d.Max(), "%+v", d)
+	}
+}
@@ -0,0 +1,138 @@
+// Package rng provides deterministic random number streams for game logic.
+package rng
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"slices"
+)
+
+const (
+	Combat = "combat"
+	Loot   = "loot"
+	MapGen = "mapgen"
+)
+
+// Stream is an independent random sequence.
+type Stream struct {
+	*rand.Rand
+	pcg *rand.PCG
+}
+
+func newStream(seed uint64, name string) *Stream {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	pcg := rand.NewPCG(seed, h.Sum64())
+	return &Stream{Rand: rand.New(pcg), pcg: pcg}
+}
+
+// Chance returns true with probability p.
+func (s *Stream) Chance(p float64) bool {
+	return s.Float64() < p
+}
+
+// RNG holds named streams derived from a single seed.
+// Each stream only advances when it is used, so adding a call to one does not change the others.
+type RNG struct {
+	seed    uint64
+	streams map[string]*Stream
+}
+
+func New(seed uint64) *RNG {
+	return &RNG{
+		seed:    seed,
+		streams: make(map[string]*Stream),
+	}
+}
+
+func (r *RNG) Seed() uint64 {
+	return r.seed
+}
+
+func (r *RNG) Stream(name string) *Stream {
+	s, ok := r.streams[name]
+	if !ok {
+		s = newStream(r.seed, name)
+		r.streams[name] = s
+	}
+	return s
+}
+
+// MarshalBinary encodes the seed and the state of every stream used so far.
+func (r *RNG) MarshalBinary() ([]byte, error) {
+	names := make([]string, 0, len(r.streams))
+	for name := range r.streams {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	data := binary.BigEndian.AppendUint64(nil, r.seed)
+	data = binary.AppendUvarint(data, uint64(len(names)))
+	for _, name := range names {
+		state, err := r.streams[name].pcg.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("marshaling stream %q: %w", name, err)
+		}
+		data = appendBytes(data, []byte(name))
+		data = appendBytes(data, state)
+	}
+	return data, nil
+}
+
+func (r *RNG) UnmarshalBinary(data []byte) error {
+	if len(data) < 8 {
+		return fmt.Errorf("unmarshaling rng: short seed")
+	}
+	seed := binary.BigEndian.Uint64(data)
+	data = data[8:]
+	n, data, err := readUvarint(data)
+	if err != nil {
+		return fmt.Errorf("unmarshaling rng: stream count: %w", err)
+	}
+	streams := make(map[string]*Stream, n)
+	for i := uint64(0); i < n; i++ {
+		var name, state []byte
+		if name, data, err = readBytes(data); err != nil {
+			return fmt.Errorf("unmarshaling rng: stream %d name: %w", i, err)
+		}
+		if state, data, err = readBytes(data); err != nil {
+			return fmt.Errorf("unmarshaling rng: stream %q state: %w", name, err)
+		}
+		s := newStream(seed, string(name))
+		if err := s.pcg.UnmarshalBinary(state); err != nil {
+			return fmt.Errorf("unmarshaling rng: stream %q state: %w", name, err)
+		}
+		streams[string(name)] = s
+	}
+	if len(data) != 0 {
+		return fmt.Errorf("unmarshaling rng: %d trailing bytes", len(data))
+	}
+	r.seed = seed
+	r.streams = streams
+	return nil
+}
+
+func appendBytes(data []byte, b []byte) []byte {
+	data = binary.AppendUvarint(data, uint64(len(b)))
+	return append(data, b...)
+}
+
+func readUvarint(data []byte) (uint64, []byte, error) {
+	v, n := binary.Uvarint(data)
+	if n <= 0 {
+		return 0, nil, fmt.Errorf("invalid varint")
+	}
+	return v, data[n:], nil
+}
+
+func readBytes(data []byte) ([]byte, []byte, error) {
+	n, data, err := readUvarint(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if uint64(len(data)) < n {
+		return nil, nil, fmt.Errorf("short data, want %d bytes, got %d", n, len(data))
+	}
+	return data[:n], data[n:], nil
+}
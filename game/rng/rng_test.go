@@ -0,0 +1,56 @@
+package rng
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func draw(s *Stream, n int) []uint64 {
+	var values []uint64
+	for range n {
+		values = append(values, s.Uint64())
+	}
+	return values
+}
+
+func TestRNG_Streams(t *testing.T) {
+	a, b := New(42), New(42)
+	require.Equal(t, draw(a.Stream(Combat), 5), draw(b.Stream(Combat), 5))
+	require.NotEqual(t, draw(New(42).Stream(Combat), 5), draw(New(43).Stream(Combat), 5))
+	require.NotEqual(t, draw(New(42).Stream(Combat), 5), draw(New(42).Stream(Loot), 5))
+
+	// Using another stream does not desync this one.
+	a, b = New(7), New(7)
+	draw(a.Stream(MapGen), 100)
+	require.Equal(t, draw(a.Stream(Loot), 5), draw(b.Stream(Loot), 5))
+	require.Same(t, a.Stream(Loot), a.Stream(Loot))
+	require.Equal(t, uint64(7), a.Seed())
+}
+
+func TestRNG_MarshalBinary(t *testing.T) {
+	r := New(1234)
+	draw(r.Stream(Combat), 3)
+	draw(r.Stream(Loot), 10)
+	data, err := r.MarshalBinary()
+	require.NoError(t, err)
+
+	restored := New(0)
+	require.NoError(t, restored.UnmarshalBinary(data))
+	require.Equal(t, r.Seed(), restored.Seed())
+	require.Equal(t, draw(r.Stream(Combat), 5), draw(restored.Stream(Combat), 5))
+	require.Equal(t, draw(r.Stream(Loot), 5), draw(restored.Stream(Loot), 5))
+	require.Equal(t, draw(r.Stream(MapGen), 5), draw(restored.Stream(MapGen), 5))
+
+	for _, bad := range [][]byte{nil, data[:7], data[:len(data)-1], append(data, 0)} {
+		require.Error(t, New(0).UnmarshalBinary(bad))
+	}
+}
+
+func TestStream_Chance(t *testing.T) {
+	s := New(1).Stream(Combat)
+	for range 100 {
+		require.False(t, s.Chance(0))
+		require.True(t, s.Chance(1))
+	}
+}
@@ -0,0 +1,49 @@
+package rng
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Table picks values at random, in proportion to their weights.
+type Table[T any] struct {
+	values []T
+	totals []int
+}
+
+func NewTable[T any]() *Table[T] {
+	return &Table[T]{}
+}
+
+// Add adds a value with the given weight. Weights must be positive.
+func (t *Table[T]) Add(weight int, value T) *Table[T] {
+	if weight <= 0 {
+		panic(fmt.Errorf("adding %v to table: invalid weight %d", value, weight))
+	}
+	t.values = append(t.values, value)
+	t.totals = append(t.totals, t.Total()+weight)
+	return t
+}
+
+func (t *Table[T]) Len() int {
+	return len(t.values)
+}
+
+func (t *Table[T]) Total() int {
+	if len(t.totals) == 0 {
+		return 0
+	}
+	return t.totals[len(t.totals)-1]
+}
+
+// Pick panics on an empty table.
+func (t *Table[T]) Pick(s *Stream) T {
+	if len(t.values) == 0 {
+		panic(fmt.Errorf("picking from empty table"))
+	}
+	n := s.IntN(t.Total())
+	i := sort.Search(len(t.totals), func(i int) bool {
+		return t.totals[i] > n
+	})
+	return t.values[i]
+}
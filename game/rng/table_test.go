@@ -0,0 +1,22 @@
+package rng
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTable(t *testing.T) {
+	table := NewTable[string]().Add(1, "gem").Add(3, "coin")
+	require.Equal(t, 2, table.Len())
+	require.Equal(t, 4, table.Total())
+	s := New(5).Stream(Loot)
+	counts := make(map[string]int)
+	for range 4000 {
+		counts[table.Pick(s)]++
+	}
+	require.InDelta(t, 1000, counts["gem"], 150)
+	require.InDelta(t, 3000, counts["coin"], 150)
+	require.Panics(t, func() { NewTable[int]().Pick(s) })
+	require.Panics(t, func() { NewTable[int]().Add(0, 1) })
+}
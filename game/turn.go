@@ -0,0 +1,131 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/PieterD/boevig/ecs"
+	"github.com/PieterD/boevig/rang"
+)
+
+// EnergyIndex orders entities by energy, most first, then by EntityID.
+// It holds the negated energy, since ties have to be broken by ascending EntityID.
+const EnergyIndex = "game.Energy"
+
+// Speed is the energy an entity gains every tick.
+type Speed struct {
+	ecs.ComponentHeader[Speed, *Speed]
+	Value int
+}
+
+// Energy accumulates with Speed, and is spent by acting.
+type Energy struct {
+	ecs.ComponentHeader[Energy, *Energy]
+	Value int
+}
+
+func (e Energy) Index() []ecs.Indexer {
+	return []ecs.Indexer{
+		ecs.ORD(EnergyIndex, -e.Value),
+	}
+}
+
+// DefaultThreshold is the energy an entity needs to act.
+const DefaultThreshold = 100
+
+// Scheduler decides whose turn it is. Entities with a Speed gain energy every tick,
+// and whoever has the most energy at or above the threshold acts next.
+type Scheduler struct {
+	db        *ecs.DB
+	threshold int
+}
+
+func NewScheduler(db *ecs.DB) *Scheduler {
+	return &Scheduler{
+		db:        db,
+		threshold: DefaultThreshold,
+	}
+}
+
+func (s *Scheduler) Threshold() int {
+	return s.threshold
+}
+
+// Next returns the entity to act next, ticking until someone has enough energy.
+// It returns false if nobody has energy to act and nobody gains any.
+func (s *Scheduler) Next() (ecs.EntityID, bool) {
+	for {
+		var energy Energy
+		id, ok := rang.First(s.db.Search().Components(&energy).OrderBy(ecs.Asc[int](EnergyIndex)).Limit(1).Done())
+		if ok && energy.Value >= s.threshold {
+			return id, true
+		}
+		if !s.skip() {
+			return 0, false
+		}
+	}
+}
+
+// skip ticks until at least one entity reaches the threshold, all at once.
+func (s *Scheduler) skip() bool {
+	ticks := 0
+	var speed Speed
+	for id := range s.db.Search().Components(&speed).Done() {
+		if speed.Value <= 0 {
+			continue
+		}
+		var energy Energy
+		s.db.Get(id, &energy)
+		need := max(1, (s.threshold-energy.Value+speed.Value-1)/speed.Value)
+		if ticks == 0 || need < ticks {
+			ticks = need
+		}
+	}
+	if ticks == 0 {
+		return false
+	}
+	s.Tick(ticks)
+	return true
+}
+
+// Tick gives every entity with a Speed its energy for n ticks.
+func (s *Scheduler) Tick(n int) {
+	var speed Speed
+	ids := rang.ToSlice(s.db.Search().Components(&speed).Done())
+	for _, id := range ids {
+		var energy Energy
+		s.db.Get(id, &speed)
+		s.db.Get(id, &energy)
+		energy.Value += n * speed.Value
+		s.db.Set(id, energy)
+	}
+}
+
+// Spend takes the cost of an action from the entity's energy.
+func (s *Scheduler) Spend(id ecs.EntityID, cost int) {
+	var energy Energy
+	s.db.Get(id, &energy)
+	energy.Value -= cost
+	s.db.Set(id, energy)
+}
+
+// Run lets entities act until it is the player's turn, and returns the player.
+// act performs an entity's turn and returns the energy it cost, which must be positive.
+// The player's turn is left to the caller, who waits for input and calls Spend.
+// Run returns false if nobody can act anymore.
+func (s *Scheduler) Run(act func(id ecs.EntityID) int) (ecs.EntityID, bool) {
+	for {
+		id, ok := s.Next()
+		if !ok {
+			return 0, false
+		}
+		var player Player
+		if s.db.Get(id, &player) {
+			return id, true
+		}
+		cost := act(id)
+		if cost <= 0 {
+			panic(fmt.Errorf("running turn of %v: action cost %d, must be positive", id, cost))
+		}
+		s.Spend(id, cost)
+	}
+}
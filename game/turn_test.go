@@ -0,0 +1,87 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/PieterD/boevig/ecs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler(t *testing.T) {
+	t.Run("speed", func(t *testing.T) {
+		db := ecs.New()
+		fast := db.NewEntity(Speed{Value: 20})
+		slow := db.NewEntity(Speed{Value: 10})
+		s := NewScheduler(db)
+		var turns []ecs.EntityID
+		for i := 0; i < 6; i++ {
+			id, ok := s.Next()
+			require.True(t, ok)
+			turns = append(turns, id)
+			s.Spend(id, s.Threshold())
+		}
+		require.Equal(t, []ecs.EntityID{fast, fast, slow, fast, fast, slow}, turns)
+	})
+	t.Run("ties by entity id", func(t *testing.T) {
+		db := ecs.New()
+		var ids []ecs.EntityID
+		for i := 0; i < 3; i++ {
+			ids = append(ids, db.NewEntity(Speed{Value: 50}))
+		}
+		s := NewScheduler(db)
+		var turns []ecs.EntityID
+		for i := 0; i < 6; i++ {
+			id, ok := s.Next()
+			require.True(t, ok)
+			turns = append(turns, id)
+			s.Spend(id, s.Threshold())
+		}
+		require.Equal(t, append(ids, ids...), turns)
+	})
+	t.Run("most energy first", func(t *testing.T) {
+		db := ecs.New()
+		db.NewEntity(Speed{Value: 10}, Energy{Value: 120})
+		rich := db.NewEntity(Speed{Value: 10}, Energy{Value: 150})
+		s := NewScheduler(db)
+		id, ok := s.Next()
+		require.True(t, ok)
+		require.Equal(t, rich, id)
+	})
+	t.Run("nobody can act", func(t *testing.T) {
+		db := ecs.New()
+		db.NewEntity(Speed{Value: 0})
+		_, ok := NewScheduler(db).Next()
+		require.False(t, ok)
+	})
+	t.Run("run until player", func(t *testing.T) {
+		db := ecs.New()
+		player := db.NewEntity(Player{}, Speed{Value: 10})
+		monster := db.NewEntity(Speed{Value: 30})
+		s := NewScheduler(db)
+		acted := 0
+		act := func(id ecs.EntityID) int {
+			require.Equal(t, monster, id)
+			acted++
+			return 100
+		}
+		id, ok := s.Run(act)
+		require.True(t, ok)
+		require.Equal(t, player, id)
+		// After two monster turns both have 100 energy, and the player has the lower ID.
+		require.Equal(t, 2, acted)
+		// The player's turn waits for input, running again does not skip it.
+		id, ok = s.Run(act)
+		require.True(t, ok)
+		require.Equal(t, player, id)
+		require.Equal(t, 2, acted)
+		s.Spend(player, 100)
+		id, ok = s.Run(act)
+		require.True(t, ok)
+		require.Equal(t, player, id)
+		require.Equal(t, 5, acted)
+		require.Panics(t, func() {
+			s.Spend(player, 100)
+			s.Run(func(ecs.EntityID) int { return 0 })
+		})
+	})
+}
@@ -0,0 +1,555 @@
+package rang
+
+import (
+	"cmp"
+	"iter"
+	"math/bits"
+	"sort"
+)
+
+type Unsigned interface {
+	~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+const (
+	bitmapArrayMax = 4096
+	bitmapWords    = 1 << 16 / 64
+)
+
+// Bitmap is a compressed set of unsigned integers, split into containers of 1<<16 values.
+// Sparse containers are sorted arrays, dense containers are bitsets.
+type Bitmap[T Unsigned] struct {
+	containers []*bitmapContainer
+}
+
+func NewBitmap[T Unsigned](values ...T) *Bitmap[T] {
+	b := &Bitmap[T]{}
+	for _, v := range values {
+		b.Add(v)
+	}
+	return b
+}
+
+func (b *Bitmap[T]) Add(v T) bool {
+	key, low := bitmapSplit(v)
+	i, ok := b.find(key)
+	if !ok {
+		c := &bitmapContainer{key: key}
+		b.containers = append(b.containers, nil)
+		copy(b.containers[i+1:], b.containers[i:])
+		b.containers[i] = c
+	}
+	return b.containers[i].add(low)
+}
+
+func (b *Bitmap[T]) Remove(v T) bool {
+	key, low := bitmapSplit(v)
+	i, ok := b.find(key)
+	if !ok {
+		return false
+	}
+	c := b.containers[i]
+	if !c.remove(low) {
+		return false
+	}
+	if c.n == 0 {
+		b.containers = append(b.containers[:i], b.containers[i+1:]...)
+	}
+	return true
+}
+
+func (b *Bitmap[T]) Contains(v T) bool {
+	key, low := bitmapSplit(v)
+	i, ok := b.find(key)
+	if !ok {
+		return false
+	}
+	return b.containers[i].contains(low)
+}
+
+func (b *Bitmap[T]) Len() int {
+	n := 0
+	for _, c := range b.containers {
+		n += c.n
+	}
+	return n
+}
+
+func (b *Bitmap[T]) Clone() *Bitmap[T] {
+	clone := &Bitmap[T]{containers: make([]*bitmapContainer, len(b.containers))}
+	for i, c := range b.containers {
+		clone.containers[i] = c.clone()
+	}
+	return clone
+}
+
+// And returns the intersection of b and other.
+func (b *Bitmap[T]) And(other *Bitmap[T]) *Bitmap[T] {
+	result := &Bitmap[T]{}
+	i, j := 0, 0
+	for i < len(b.containers) && j < len(other.containers) {
+		left, right := b.containers[i], other.containers[j]
+		switch {
+		case left.key < right.key:
+			i++
+		case right.key < left.key:
+			j++
+		default:
+			if c := containerAnd(left, right); c.n > 0 {
+				result.containers = append(result.containers, c)
+			}
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+// Or returns the union of b and other.
+func (b *Bitmap[T]) Or(other *Bitmap[T]) *Bitmap[T] {
+	result := &Bitmap[T]{}
+	i, j := 0, 0
+	for i < len(b.containers) || j < len(other.containers) {
+		switch {
+		case j == len(other.containers) || (i < len(b.containers) && b.containers[i].key < other.containers[j].key):
+			result.containers = append(result.containers, b.containers[i].clone())
+			i++
+		case i == len(b.containers) || other.containers[j].key < b.containers[i].key:
+			result.containers = append(result.containers, other.containers[j].clone())
+			j++
+		default:
+			result.containers = append(result.containers, containerOr(b.containers[i], other.containers[j]))
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+// AndNot returns the values in b that are not in other.
+func (b *Bitmap[T]) AndNot(other *Bitmap[T]) *Bitmap[T] {
+	result := &Bitmap[T]{}
+	j := 0
+	for _, left := range b.containers {
+		for j < len(other.containers) && other.containers[j].key < left.key {
+			j++
+		}
+		if j == len(other.containers) || other.containers[j].key != left.key {
+			result.containers = append(result.containers, left.clone())
+			continue
+		}
+		if c := containerAndNot(left, other.containers[j]); c.n > 0 {
+			result.containers = append(result.containers, c)
+		}
+	}
+	return result
+}
+
+// Next returns the smallest value in b that is at least from.
+func (b *Bitmap[T]) Next(from T) (T, bool) {
+	key, low := bitmapSplit(from)
+	i, _ := b.find(key)
+	for ; i < len(b.containers); i++ {
+		c := b.containers[i]
+		if c.key != key {
+			low = 0
+		}
+		if next, ok := c.next(low); ok {
+			return T(c.key<<16 | uint64(next)), true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// Cursor walks the values with Next, so it finds its place again if b is modified in between.
+func (b *Bitmap[T]) Cursor() Cursor[T] {
+	return &bitmapCursor[T]{b: b}
+}
+
+type bitmapCursor[T Unsigned] struct {
+	b       *Bitmap[T]
+	value   T
+	started bool
+	done    bool
+}
+
+func (c *bitmapCursor[T]) Next() bool {
+	if !c.started {
+		return c.Seek(0)
+	}
+	if c.done || c.value == ^T(0) {
+		c.done = true
+		return false
+	}
+	return c.seek(c.value + 1)
+}
+
+func (c *bitmapCursor[T]) Seek(to T) bool {
+	if c.done {
+		return false
+	}
+	if c.started && c.value >= to {
+		return true
+	}
+	return c.seek(to)
+}
+
+func (c *bitmapCursor[T]) seek(to T) bool {
+	c.started = true
+	v, ok := c.b.Next(to)
+	c.value, c.done = v, !ok
+	return ok
+}
+
+func (c *bitmapCursor[T]) Value() T {
+	return c.value
+}
+
+func (b *Bitmap[T]) Values() iter.Seq[T] {
+	return b.ascend(nil)
+}
+
+func (b *Bitmap[T]) SeekSeq() iter.Seq[Seekable[T]] {
+	return NewOrdered(func(x, y T) bool { return x < y }).CursorSeq(b.Cursor)
+}
+
+// ReverseSeekSeq yields the values in descending order.
+func (b *Bitmap[T]) ReverseSeekSeq() iter.Seq[Seekable[T]] {
+	return NewOrdered(func(x, y T) bool { return x < y }).Reverse().SeekIterator(b.descend)
+}
+
+func (b *Bitmap[T]) descend(start *T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		i := len(b.containers) - 1
+		startLow := uint16(1<<16 - 1)
+		if start != nil {
+			key, low := bitmapSplit(*start)
+			var ok bool
+			i, ok = b.find(key)
+			if ok {
+				startLow = low
+			} else {
+				i--
+			}
+		}
+		for ; i >= 0; i-- {
+			c := b.containers[i]
+			high := c.key << 16
+			for low := range c.descend(startLow) {
+				if !yield(T(high | uint64(low))) {
+					return
+				}
+			}
+			startLow = 1<<16 - 1
+		}
+	}
+}
+
+func (b *Bitmap[T]) ascend(start *T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		i := 0
+		var startLow uint16
+		if start != nil {
+			key, low := bitmapSplit(*start)
+			i, _ = b.find(key)
+			if i < len(b.containers) && b.containers[i].key == key {
+				startLow = low
+			}
+		}
+		for ; i < len(b.containers); i++ {
+			c := b.containers[i]
+			high := c.key << 16
+			for low := range c.ascend(startLow) {
+				if !yield(T(high | uint64(low))) {
+					return
+				}
+			}
+			startLow = 0
+		}
+	}
+}
+
+func (b *Bitmap[T]) find(key uint64) (int, bool) {
+	i := sort.Search(len(b.containers), func(i int) bool {
+		return b.containers[i].key >= key
+	})
+	return i, i < len(b.containers) && b.containers[i].key == key
+}
+
+func bitmapSplit[T Unsigned](v T) (uint64, uint16) {
+	return uint64(v) >> 16, uint16(v)
+}
+
+type bitmapContainer struct {
+	key   uint64
+	n     int
+	array []uint16
+	bits  []uint64
+}
+
+func (c *bitmapContainer) add(low uint16) bool {
+	if c.bits != nil {
+		word, mask := low/64, uint64(1)<<(low%64)
+		if c.bits[word]&mask != 0 {
+			return false
+		}
+		c.bits[word] |= mask
+		c.n++
+		return true
+	}
+	i := sort.Search(len(c.array), func(i int) bool { return c.array[i] >= low })
+	if i < len(c.array) && c.array[i] == low {
+		return false
+	}
+	c.array = append(c.array, 0)
+	copy(c.array[i+1:], c.array[i:])
+	c.array[i] = low
+	c.n++
+	if c.n > bitmapArrayMax {
+		c.toBits()
+	}
+	return true
+}
+
+func (c *bitmapContainer) remove(low uint16) bool {
+	if c.bits != nil {
+		word, mask := low/64, uint64(1)<<(low%64)
+		if c.bits[word]&mask == 0 {
+			return false
+		}
+		c.bits[word] &^= mask
+		c.n--
+		if c.n <= bitmapArrayMax {
+			c.toArray()
+		}
+		return true
+	}
+	i := sort.Search(len(c.array), func(i int) bool { return c.array[i] >= low })
+	if i == len(c.array) || c.array[i] != low {
+		return false
+	}
+	c.array = append(c.array[:i], c.array[i+1:]...)
+	c.n--
+	return true
+}
+
+func (c *bitmapContainer) contains(low uint16) bool {
+	if c.bits != nil {
+		return c.bits[low/64]&(uint64(1)<<(low%64)) != 0
+	}
+	i := sort.Search(len(c.array), func(i int) bool { return c.array[i] >= low })
+	return i < len(c.array) && c.array[i] == low
+}
+
+func (c *bitmapContainer) next(from uint16) (uint16, bool) {
+	if c.bits == nil {
+		i := sort.Search(len(c.array), func(i int) bool { return c.array[i] >= from })
+		if i == len(c.array) {
+			return 0, false
+		}
+		return c.array[i], true
+	}
+	word := int(from / 64)
+	w := c.bits[word] &^ (uint64(1)<<(from%64) - 1)
+	for w == 0 {
+		word++
+		if word == bitmapWords {
+			return 0, false
+		}
+		w = c.bits[word]
+	}
+	return uint16(word*64 + bits.TrailingZeros64(w)), true
+}
+
+func (c *bitmapContainer) ascend(start uint16) iter.Seq[uint16] {
+	return func(yield func(uint16) bool) {
+		if c.bits == nil {
+			i := sort.Search(len(c.array), func(i int) bool { return c.array[i] >= start })
+			for _, low := range c.array[i:] {
+				if !yield(low) {
+					return
+				}
+			}
+			return
+		}
+		word := int(start / 64)
+		w := c.bits[word] &^ (uint64(1)<<(start%64) - 1)
+		for {
+			for w != 0 {
+				bit := bits.TrailingZeros64(w)
+				if !yield(uint16(word*64 + bit)) {
+					return
+				}
+				w &= w - 1
+			}
+			word++
+			if word == bitmapWords {
+				return
+			}
+			w = c.bits[word]
+		}
+	}
+}
+
+func (c *bitmapContainer) descend(start uint16) iter.Seq[uint16] {
+	return func(yield func(uint16) bool) {
+		if c.bits == nil {
+			i := sort.Search(len(c.array), func(i int) bool { return c.array[i] > start })
+			for i--; i >= 0; i-- {
+				if !yield(c.array[i]) {
+					return
+				}
+			}
+			return
+		}
+		word := int(start / 64)
+		w := c.bits[word]
+		if bit := start % 64; bit < 63 {
+			w &= uint64(1)<<(bit+1) - 1
+		}
+		for {
+			for w != 0 {
+				bit := 63 - bits.LeadingZeros64(w)
+				if !yield(uint16(word*64 + bit)) {
+					return
+				}
+				w &^= uint64(1) << bit
+			}
+			word--
+			if word < 0 {
+				return
+			}
+			w = c.bits[word]
+		}
+	}
+}
+
+func (c *bitmapContainer) clone() *bitmapContainer {
+	clone := &bitmapContainer{key: c.key, n: c.n}
+	if c.bits != nil {
+		clone.bits = append([]uint64(nil), c.bits...)
+	} else {
+		clone.array = append([]uint16(nil), c.array...)
+	}
+	return clone
+}
+
+func (c *bitmapContainer) toBits() {
+	c.bits = make([]uint64, bitmapWords)
+	for _, low := range c.array {
+		c.bits[low/64] |= uint64(1) << (low % 64)
+	}
+	c.array = nil
+}
+
+func (c *bitmapContainer) toArray() {
+	c.array = make([]uint16, 0, c.n)
+	for low := range c.ascend(0) {
+		c.array = append(c.array, low)
+	}
+	c.bits = nil
+}
+
+// normalize recounts a bitset container and converts it to an array if it has become sparse.
+func (c *bitmapContainer) normalize() *bitmapContainer {
+	if c.bits == nil {
+		c.n = len(c.array)
+		return c
+	}
+	c.n = 0
+	for _, w := range c.bits {
+		c.n += bits.OnesCount64(w)
+	}
+	if c.n <= bitmapArrayMax {
+		c.toArray()
+	}
+	return c
+}
+
+func containerAnd(left, right *bitmapContainer) *bitmapContainer {
+	result := &bitmapContainer{key: left.key}
+	switch {
+	case left.bits != nil && right.bits != nil:
+		result.bits = make([]uint64, bitmapWords)
+		for i := range result.bits {
+			result.bits[i] = left.bits[i] & right.bits[i]
+		}
+	case left.bits != nil:
+		result.array = filterArray(right.array, left, true)
+	case right.bits != nil:
+		result.array = filterArray(left.array, right, true)
+	case len(left.array) <= len(right.array):
+		result.array = intersectSorted(cmp.Less[uint16], left.array, right.array)
+	default:
+		result.array = intersectSorted(cmp.Less[uint16], right.array, left.array)
+	}
+	return result.normalize()
+}
+
+func containerOr(left, right *bitmapContainer) *bitmapContainer {
+	result := &bitmapContainer{key: left.key}
+	if left.bits == nil && right.bits == nil {
+		i, j := 0, 0
+		for i < len(left.array) || j < len(right.array) {
+			switch {
+			case j == len(right.array) || (i < len(left.array) && left.array[i] < right.array[j]):
+				result.array = append(result.array, left.array[i])
+				i++
+			case i == len(left.array) || right.array[j] < left.array[i]:
+				result.array = append(result.array, right.array[j])
+				j++
+			default:
+				result.array = append(result.array, left.array[i])
+				i++
+				j++
+			}
+		}
+		if len(result.array) > bitmapArrayMax {
+			result.toBits()
+		}
+		return result.normalize()
+	}
+	result.bits = make([]uint64, bitmapWords)
+	for _, c := range []*bitmapContainer{left, right} {
+		if c.bits != nil {
+			for i, w := range c.bits {
+				result.bits[i] |= w
+			}
+			continue
+		}
+		for _, low := range c.array {
+			result.bits[low/64] |= uint64(1) << (low % 64)
+		}
+	}
+	return result.normalize()
+}
+
+func containerAndNot(left, right *bitmapContainer) *bitmapContainer {
+	result := &bitmapContainer{key: left.key}
+	switch {
+	case left.bits == nil:
+		result.array = filterArray(left.array, right, false)
+	case right.bits != nil:
+		result.bits = make([]uint64, bitmapWords)
+		for i := range result.bits {
+			result.bits[i] = left.bits[i] &^ right.bits[i]
+		}
+	default:
+		result.bits = append([]uint64(nil), left.bits...)
+		for _, low := range right.array {
+			result.bits[low/64] &^= uint64(1) << (low % 64)
+		}
+	}
+	return result.normalize()
+}
+
+func filterArray(array []uint16, c *bitmapContainer, keep bool) []uint16 {
+	var result []uint16
+	for _, low := range array {
+		if c.contains(low) == keep {
+			result = append(result, low)
+		}
+	}
+	return result
+}
@@ -0,0 +1,136 @@
+package rang
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitmap_AddRemove(t *testing.T) {
+	b := NewBitmap[uint64]()
+	require.True(t, b.Add(5))
+	require.False(t, b.Add(5))
+	require.True(t, b.Add(1<<20))
+	require.True(t, b.Add(3))
+	require.Equal(t, 3, b.Len())
+	require.True(t, b.Contains(5))
+	require.False(t, b.Contains(4))
+	require.Equal(t, []uint64{3, 5, 1 << 20}, ToSlice(b.Values()))
+	require.True(t, b.Remove(5))
+	require.False(t, b.Remove(5))
+	require.True(t, b.Remove(1<<20))
+	require.Equal(t, []uint64{3}, ToSlice(b.Values()))
+	require.Len(t, b.containers, 1)
+}
+
+func TestBitmap_Dense(t *testing.T) {
+	b := NewBitmap[uint32]()
+	var model []uint32
+	for v := uint32(0); v < 20000; v += 2 {
+		b.Add(v)
+		model = append(model, v)
+	}
+	require.NotNil(t, b.containers[0].bits)
+	require.Equal(t, len(model), b.Len())
+	require.Equal(t, model, ToSlice(b.Values()))
+	for v := uint32(0); v < 20000; v += 4 {
+		b.Remove(v)
+	}
+	require.NotNil(t, b.containers[0].bits)
+	require.Equal(t, 5000, b.Len())
+	for v := uint32(2); v < 20000; v += 8 {
+		b.Remove(v)
+	}
+	require.Nil(t, b.containers[0].bits)
+	require.Equal(t, 2500, b.Len())
+	require.True(t, b.Contains(6))
+	require.False(t, b.Contains(4))
+	require.False(t, b.Contains(2))
+}
+
+func TestBitmap_SetOperations(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, size := range []int{10, 1000, 20000} {
+		left, leftModel := randomBitmap(rnd, size, 1<<18)
+		right, rightModel := randomBitmap(rnd, size, 1<<18)
+		var and, or, andNot []uint64
+		for v := range leftModel {
+			if rightModel[v] {
+				and = append(and, v)
+			} else {
+				andNot = append(andNot, v)
+			}
+			or = append(or, v)
+		}
+		for v := range rightModel {
+			if !leftModel[v] {
+				or = append(or, v)
+			}
+		}
+		for _, s := range [][]uint64{and, or, andNot} {
+			sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+		}
+		require.Equal(t, and, ToSlice(left.And(right).Values()))
+		require.Equal(t, or, ToSlice(left.Or(right).Values()))
+		require.Equal(t, andNot, ToSlice(left.AndNot(right).Values()))
+		require.Equal(t, len(and), left.And(right).Len())
+		require.Equal(t, and, ToSlice(UnSeek(NewOrdered(func(a, b uint64) bool { return a < b }).Intersect(left.SeekSeq(), right.SeekSeq()))))
+	}
+}
+
+func TestBitmap_SeekSeq(t *testing.T) {
+	b := NewBitmap[uint64](1, 2, 3, 1<<16+1, 1<<16+5, 1<<17)
+	var got []uint64
+	for v := range b.SeekSeq() {
+		switch v.Value() {
+		case 2:
+			v.Seek(1 << 16)
+			continue
+		case 1<<16 + 1:
+			v.Seek(1<<16 + 100)
+			continue
+		}
+		got = append(got, v.Value())
+	}
+	require.Equal(t, []uint64{1, 1 << 17}, got)
+}
+
+func randomBitmap(rnd *rand.Rand, size int, max int64) (*Bitmap[uint64], map[uint64]bool) {
+	b := NewBitmap[uint64]()
+	model := make(map[uint64]bool)
+	for i := 0; i < size; i++ {
+		v := uint64(rnd.Int63n(max))
+		b.Add(v)
+		model[v] = true
+	}
+	return b, model
+}
+
+func TestBitmap_ReverseSeekSeq(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	for _, size := range []int{10, 20000} {
+		b, model := randomBitmap(rnd, size, 1<<18)
+		var expected []uint64
+		for v := range model {
+			expected = append(expected, v)
+		}
+		sort.Slice(expected, func(i, j int) bool { return expected[i] > expected[j] })
+		require.Equal(t, expected, ToSlice(UnSeek(b.ReverseSeekSeq())))
+	}
+	b := NewBitmap[uint64](1, 2, 3, 63, 64, 1<<16+1, 1<<16+5, 1<<17)
+	var got []uint64
+	for v := range b.ReverseSeekSeq() {
+		switch v.Value() {
+		case 1 << 17:
+			v.Seek(1<<16 + 2)
+			continue
+		case 64:
+			v.Seek(2)
+			continue
+		}
+		got = append(got, v.Value())
+	}
+	require.Equal(t, []uint64{1<<16 + 1, 2, 1}, got)
+}
@@ -0,0 +1,237 @@
+package rang
+
+import (
+	"cmp"
+	"iter"
+)
+
+// Cursor is a seekable sequence that is pulled instead of pushed,
+// so combining cursors needs no coroutines.
+// A cursor starts before its first value, Next and Seek report whether it has one.
+type Cursor[T any] interface {
+	Next() bool
+	// Seek moves to the first value not less than to. It never moves backwards.
+	Seek(to T) bool
+	Value() T
+}
+
+// CursorSeq yields the values of a new cursor for each iteration.
+func (o Ordered[T]) CursorSeq(newCursor func() Cursor[T]) iter.Seq[Seekable[T]] {
+	return func(yield func(Seekable[T]) bool) {
+		c := newCursor()
+		seekable := o.newSeekable()
+		ok := c.Next()
+		for ok {
+			seekable.value = c.Value()
+			if !yield(seekable) {
+				return
+			}
+			if *seekable.seek {
+				*seekable.seek = false
+				ok = c.Seek(*seekable.seekValue)
+				continue
+			}
+			ok = c.Next()
+		}
+	}
+}
+
+// PullCursor turns a seekable sequence into a cursor with iter.Pull.
+// It is the fallback for sequences that have no native cursor, stop must be called when done.
+func (o Ordered[T]) PullCursor(seq iter.Seq[Seekable[T]]) (c Cursor[T], stop func()) {
+	next, stop := iter.Pull(seq)
+	return &pullCursor[T]{less: o.less, next: next}, stop
+}
+
+type pullCursor[T any] struct {
+	less    func(a, b T) bool
+	next    func() (Seekable[T], bool)
+	current Seekable[T]
+	started bool
+	done    bool
+}
+
+func (c *pullCursor[T]) Next() bool {
+	if c.done {
+		return false
+	}
+	c.started = true
+	v, ok := c.next()
+	c.current, c.done = v, !ok
+	return ok
+}
+
+func (c *pullCursor[T]) Seek(to T) bool {
+	if !c.started && !c.Next() {
+		return false
+	}
+	if c.done {
+		return false
+	}
+	if !c.less(c.current.Value(), to) {
+		return true
+	}
+	c.current.Seek(to)
+	return c.Next()
+}
+
+func (c *pullCursor[T]) Value() T {
+	return c.current.Value()
+}
+
+// SortedCursor is a cursor over a sorted slice, seeking gallops like FromSorted.
+func SortedCursor[T cmp.Ordered](s []T) Cursor[T] {
+	return SortedCursorFunc(s, cmp.Less[T])
+}
+
+func SortedCursorFunc[T any](s []T, less func(a, b T) bool) Cursor[T] {
+	return &sliceCursor[T]{s: s, less: less, pos: -1}
+}
+
+type sliceCursor[T any] struct {
+	s    []T
+	less func(a, b T) bool
+	pos  int
+}
+
+func (c *sliceCursor[T]) Next() bool {
+	if c.pos < len(c.s) {
+		c.pos++
+	}
+	return c.pos < len(c.s)
+}
+
+func (c *sliceCursor[T]) Seek(to T) bool {
+	if c.pos < 0 {
+		c.pos = 0
+	}
+	c.pos = gallop(c.s, c.pos, to, c.less)
+	return c.pos < len(c.s)
+}
+
+func (c *sliceCursor[T]) Value() T {
+	return c.s[c.pos]
+}
+
+// IntersectCursors is Intersect on cursors.
+// The first cursor drives the intersection, so it should be the smallest.
+func (o Ordered[T]) IntersectCursors(cursors ...Cursor[T]) Cursor[T] {
+	if len(cursors) == 1 {
+		return cursors[0]
+	}
+	return &intersectCursor[T]{less: o.less, cursors: cursors}
+}
+
+type intersectCursor[T any] struct {
+	less    func(a, b T) bool
+	cursors []Cursor[T]
+}
+
+func (c *intersectCursor[T]) Next() bool {
+	if len(c.cursors) == 0 || !c.cursors[0].Next() {
+		return false
+	}
+	return c.align()
+}
+
+func (c *intersectCursor[T]) Seek(to T) bool {
+	if len(c.cursors) == 0 || !c.cursors[0].Seek(to) {
+		return false
+	}
+	return c.align()
+}
+
+// align seeks the cursors until they agree on the driver's value.
+func (c *intersectCursor[T]) align() bool {
+	driver := c.cursors[0]
+	for {
+		candidate := driver.Value()
+		agreed := true
+		for _, cursor := range c.cursors[1:] {
+			if !cursor.Seek(candidate) {
+				return false
+			}
+			if v := cursor.Value(); c.less(candidate, v) {
+				if !driver.Seek(v) {
+					return false
+				}
+				agreed = false
+				break
+			}
+		}
+		if agreed {
+			return true
+		}
+	}
+}
+
+func (c *intersectCursor[T]) Value() T {
+	return c.cursors[0].Value()
+}
+
+// UnionCursors is Union on cursors.
+func (o Ordered[T]) UnionCursors(cursors ...Cursor[T]) Cursor[T] {
+	return &unionCursor[T]{
+		less:    o.less,
+		cursors: cursors,
+		alive:   make([]bool, len(cursors)),
+	}
+}
+
+type unionCursor[T any] struct {
+	less    func(a, b T) bool
+	cursors []Cursor[T]
+	alive   []bool
+	value   T
+	started bool
+}
+
+func (c *unionCursor[T]) Next() bool {
+	if !c.started {
+		c.started = true
+		for i, cursor := range c.cursors {
+			c.alive[i] = cursor.Next()
+		}
+		return c.lowest()
+	}
+	for i, cursor := range c.cursors {
+		if !c.alive[i] {
+			continue
+		}
+		v := cursor.Value()
+		if c.less(c.value, v) || c.less(v, c.value) {
+			continue
+		}
+		c.alive[i] = cursor.Next()
+	}
+	return c.lowest()
+}
+
+func (c *unionCursor[T]) Seek(to T) bool {
+	for i, cursor := range c.cursors {
+		if c.started && !c.alive[i] {
+			continue
+		}
+		c.alive[i] = cursor.Seek(to)
+	}
+	c.started = true
+	return c.lowest()
+}
+
+func (c *unionCursor[T]) lowest() bool {
+	found := false
+	for i, cursor := range c.cursors {
+		if !c.alive[i] {
+			continue
+		}
+		if v := cursor.Value(); !found || c.less(v, c.value) {
+			c.value = v
+			found = true
+		}
+	}
+	return found
+}
+
+func (c *unionCursor[T]) Value() T {
+	return c.value
+}
@@ -0,0 +1,161 @@
+package rang
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func cursorSlice[T any](c Cursor[T]) []T {
+	var values []T
+	for ok := c.Next(); ok; ok = c.Next() {
+		values = append(values, c.Value())
+	}
+	return values
+}
+
+func TestCursor(t *testing.T) {
+	o := NewOrdered(func(a, b int) bool { return a < b })
+	cursors := map[string]func(s []int) Cursor[int]{
+		"sorted": SortedCursor[int],
+		"bitmap": func(s []int) Cursor[int] {
+			b := NewBitmap[uint]()
+			for _, v := range s {
+				b.Add(uint(v))
+			}
+			return &castCursor{b.Cursor()}
+		},
+		"pull": func(s []int) Cursor[int] {
+			c, stop := o.PullCursor(FromSorted(s))
+			t.Cleanup(stop)
+			return c
+		},
+	}
+	for name, newCursor := range cursors {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, []int{1, 3, 5}, cursorSlice(newCursor([]int{1, 3, 5})))
+			require.Equal(t, []int(nil), cursorSlice(newCursor(nil)))
+
+			c := newCursor([]int{2, 4, 6, 8, 100000})
+			require.True(t, c.Seek(3))
+			require.Equal(t, 4, c.Value())
+			require.True(t, c.Seek(1), "seek never moves backwards")
+			require.Equal(t, 4, c.Value())
+			require.True(t, c.Next())
+			require.Equal(t, 6, c.Value())
+			require.True(t, c.Seek(9))
+			require.Equal(t, 100000, c.Value())
+			require.False(t, c.Next())
+			require.False(t, c.Next())
+			require.False(t, c.Seek(0))
+		})
+	}
+}
+
+type castCursor struct {
+	Cursor[uint]
+}
+
+func (c *castCursor) Seek(to int) bool {
+	return c.Cursor.Seek(uint(to))
+}
+
+func (c *castCursor) Value() int {
+	return int(c.Cursor.Value())
+}
+
+func TestOrdered_IntersectCursors(t *testing.T) {
+	o := NewOrdered(func(a, b int) bool { return a < b })
+	require.Equal(t, []int{3, 4}, cursorSlice(o.IntersectCursors(SortedCursor([]int{1, 2, 3, 4}), SortedCursor([]int{3, 4, 5}))))
+	require.Equal(t, []int(nil), cursorSlice(o.IntersectCursors()))
+	rnd := rand.New(rand.NewSource(5))
+	for i := 0; i < 20; i++ {
+		a := randomSorted(rnd, 50, 300)
+		b := randomSorted(rnd, 100, 300)
+		c := randomSorted(rnd, 200, 300)
+		expected := intersectSorted(o.less, a, b, c)
+		got := cursorSlice(o.IntersectCursors(SortedCursor(a), SortedCursor(b), SortedCursor(c)))
+		require.Equal(t, expected, got)
+	}
+}
+
+func TestOrdered_UnionCursors(t *testing.T) {
+	o := NewOrdered(func(a, b int) bool { return a < b })
+	require.Equal(t, []int{1, 2, 3, 4, 5}, cursorSlice(o.UnionCursors(SortedCursor([]int{1, 3, 4}), SortedCursor([]int{2, 3, 5}))))
+	require.Equal(t, []int(nil), cursorSlice(o.UnionCursors()))
+	c := o.UnionCursors(SortedCursor([]int{1, 10, 20}), SortedCursor([]int{5, 15}))
+	require.True(t, c.Seek(6))
+	require.Equal(t, 10, c.Value())
+	require.True(t, c.Next())
+	require.Equal(t, 15, c.Value())
+	rnd := rand.New(rand.NewSource(6))
+	for i := 0; i < 20; i++ {
+		a := randomSorted(rnd, 50, 300)
+		b := randomSorted(rnd, 100, 300)
+		expected := ToSlice(UnSeek(o.Union(FromSorted(a), FromSorted(b))))
+		require.Equal(t, expected, cursorSlice(o.UnionCursors(SortedCursor(a), SortedCursor(b))))
+	}
+}
+
+func TestOrdered_CursorSeq(t *testing.T) {
+	o := NewOrdered(func(a, b int) bool { return a < b })
+	seq := o.CursorSeq(func() Cursor[int] {
+		return SortedCursor([]int{1, 2, 3, 4, 5, 6})
+	})
+	var got []int
+	for v := range seq {
+		got = append(got, v.Value())
+		if v.Value() == 2 {
+			v.Seek(5)
+		}
+	}
+	require.Equal(t, []int{1, 2, 5, 6}, got)
+	require.Equal(t, []int{1, 2, 3, 4, 5, 6}, ToSlice(UnSeek(seq)))
+	require.Equal(t, []int{2, 4}, ToSlice(UnSeek(o.Intersect(seq, FromSorted([]int{2, 4, 7})))))
+}
+
+func BenchmarkIntersect_Cursor(b *testing.B) {
+	rnd := rand.New(rand.NewSource(7))
+	small := randomSorted(rnd, 1000, 1000000)
+	large := randomSorted(rnd, 100000, 1000000)
+	o := NewOrdered(func(a, b int) bool { return a < b })
+	smallBits, largeBits := NewBitmap[uint](), NewBitmap[uint]()
+	for _, v := range small {
+		smallBits.Add(uint(v))
+	}
+	for _, v := range large {
+		largeBits.Add(uint(v))
+	}
+	ob := NewOrdered(func(a, b uint) bool { return a < b })
+	b.Run("seq sorted", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for range o.Intersect(FromSorted(small), FromSorted(large)) {
+			}
+		}
+	})
+	b.Run("cursor sorted", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			c := o.IntersectCursors(SortedCursor(small), SortedCursor(large))
+			for ok := c.Next(); ok; ok = c.Next() {
+			}
+		}
+	})
+	b.Run("seq bitmap", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for range ob.Intersect(smallBits.SeekSeq(), largeBits.SeekSeq()) {
+			}
+		}
+	})
+	b.Run("cursor bitmap", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			c := ob.IntersectCursors(smallBits.Cursor(), largeBits.Cursor())
+			for ok := c.Next(); ok; ok = c.Next() {
+			}
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package rang
+
+import "iter"
+
+// The E variants work on fallible sources, such as disk or network backed sequences.
+// A fallible sequence yields a zero value with a non-nil error once, and then stops.
+
+type SeekConstructorE[T any] func(start *T) iter.Seq2[T, error]
+
+func (o Ordered[T]) SeekIteratorE(cons SeekConstructorE[T]) iter.Seq2[Seekable[T], error] {
+	return func(yield func(Seekable[T], error) bool) {
+		seekable := o.newSeekable()
+		seq := cons(nil)
+		for {
+			for v, err := range seq {
+				if err != nil {
+					yield(Seekable[T]{}, err)
+					return
+				}
+				seekable.value = v
+				if !yield(seekable, nil) {
+					return
+				}
+				if *seekable.seek {
+					break
+				}
+			}
+			if !*seekable.seek {
+				return
+			}
+			*seekable.seek = false
+			seq = cons(seekable.seekValue)
+		}
+	}
+}
+
+// IntersectE is Intersect for fallible sequences.
+// It stops at the first error any of the seqs returns, and yields it.
+func (o Ordered[T]) IntersectE(seqs ...iter.Seq2[Seekable[T], error]) iter.Seq2[Seekable[T], error] {
+	return func(yield func(Seekable[T], error) bool) {
+		holders := newSeqHolders(seqs)
+		defer holders.Stop()
+		stopped := false
+		err := o.intersect(holders, func(s Seekable[T]) bool {
+			stopped = !yield(s, nil)
+			return !stopped
+		})
+		if err != nil && !stopped {
+			yield(Seekable[T]{}, err)
+		}
+	}
+}
+
+// UnionE is Union for fallible sequences.
+// It stops at the first error any of the seqs returns, and yields it.
+func (o Ordered[T]) UnionE(seqs ...iter.Seq2[Seekable[T], error]) iter.Seq2[Seekable[T], error] {
+	return func(yield func(Seekable[T], error) bool) {
+		holders := newSeqHolders(seqs)
+		defer holders.Stop()
+		stopped := false
+		err := o.union(holders, func(s Seekable[T]) bool {
+			stopped = !yield(s, nil)
+			return !stopped
+		})
+		if err != nil && !stopped {
+			yield(Seekable[T]{}, err)
+		}
+	}
+}
+
+// Fallible turns an infallible sequence into a fallible one that never errors.
+func Fallible[T any](seq iter.Seq[Seekable[T]]) iter.Seq2[Seekable[T], error] {
+	return func(yield func(Seekable[T], error) bool) {
+		for s := range seq {
+			if !yield(s, nil) {
+				return
+			}
+		}
+	}
+}
+
+func fallibles[T any](seqs []iter.Seq[Seekable[T]]) []iter.Seq2[Seekable[T], error] {
+	result := make([]iter.Seq2[Seekable[T], error], 0, len(seqs))
+	for _, seq := range seqs {
+		result = append(result, Fallible(seq))
+	}
+	return result
+}
+
+func UnSeekE[T any](seq iter.Seq2[Seekable[T], error]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for s, err := range seq {
+			if !yield(s.Value(), err) {
+				return
+			}
+		}
+	}
+}
+
+// ToSliceE collects a fallible sequence, stopping at the first error.
+func ToSliceE[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var vs []T
+	for v, err := range seq {
+		if err != nil {
+			return vs, err
+		}
+		vs = append(vs, v)
+	}
+	return vs, nil
+}
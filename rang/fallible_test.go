@@ -0,0 +1,75 @@
+package rang
+
+import (
+	"errors"
+	"iter"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var errTest = errors.New("test error")
+
+// failingSearch fails when it reaches failAt.
+func failingSearch(searcher *TestSearcher, failAt int) SeekConstructorE[int] {
+	return func(start *int) iter.Seq2[int, error] {
+		return func(yield func(int, error) bool) {
+			for v := range searcher.Search(start) {
+				if v == failAt {
+					yield(0, errTest)
+					return
+				}
+				if !yield(v, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func TestOrdered_Fallible(t *testing.T) {
+	o := NewOrdered(func(a, b int) bool { return a < b })
+	t.Run("seek iterator", func(t *testing.T) {
+		seq := o.SeekIteratorE(failingSearch(NewTestSearcher(1, 2, 3, 4, 5), 4))
+		got, err := ToSliceE(UnSeekE(seq))
+		require.ErrorIs(t, err, errTest)
+		require.Equal(t, []int{1, 2, 3}, got)
+	})
+	t.Run("seek past error", func(t *testing.T) {
+		seq := o.SeekIteratorE(failingSearch(NewTestSearcher(1, 2, 3, 4, 5), 2))
+		var got []int
+		for v, err := range seq {
+			require.NoError(t, err)
+			if v.Value() == 1 {
+				v.Seek(3)
+				continue
+			}
+			got = append(got, v.Value())
+		}
+		require.Equal(t, []int{3, 4, 5}, got)
+	})
+	t.Run("intersect", func(t *testing.T) {
+		left := o.SeekIteratorE(failingSearch(NewTestSearcher(1, 2, 3, 4, 5, 6), 5))
+		right := Fallible(o.SeekIterator(NewTestSearcher(2, 4, 6).Search))
+		got, err := ToSliceE(UnSeekE(o.IntersectE(left, right)))
+		require.ErrorIs(t, err, errTest)
+		require.Equal(t, []int{2, 4}, got)
+	})
+	t.Run("union", func(t *testing.T) {
+		left := Fallible(o.SeekIterator(NewTestSearcher(1, 3, 5).Search))
+		right := o.SeekIteratorE(failingSearch(NewTestSearcher(2, 4, 6), 4))
+		got, err := ToSliceE(UnSeekE(o.UnionE(left, right)))
+		require.ErrorIs(t, err, errTest)
+		// 3 is not yielded, the failed right side might have had a smaller value.
+		require.Equal(t, []int{1, 2}, got)
+	})
+	t.Run("stop before error", func(t *testing.T) {
+		left := o.SeekIteratorE(failingSearch(NewTestSearcher(1, 2, 3), 3))
+		right := Fallible(o.SeekIterator(NewTestSearcher(1, 2, 3).Search))
+		for v, err := range o.IntersectE(left, right) {
+			require.NoError(t, err)
+			require.Equal(t, 1, v.Value())
+			break
+		}
+	})
+}
@@ -1,6 +1,10 @@
 package rang
 
-import "iter"
+import (
+	"container/heap"
+	"fmt"
+	"iter"
+)
 
 func Map[FROM, TO any](seq iter.Seq[FROM], f func(FROM) TO) iter.Seq[TO] {
 	return func(yield func(TO) bool) {
@@ -28,3 +32,211 @@ func ToSlice[T any](seq iter.Seq[T]) []T {
 	}
 	return vs
 }
+
+func Filter[T any](seq iter.Seq[T], keep func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if !keep(v) {
+				continue
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// FilterSeek filters a seekable sequence on its values.
+// The yielded values can still be sought, the seek is passed on to seq.
+func FilterSeek[T any](seq iter.Seq[Seekable[T]], keep func(T) bool) iter.Seq[Seekable[T]] {
+	return Filter(seq, func(s Seekable[T]) bool {
+		return keep(s.Value())
+	})
+}
+
+// Take yields the first n values of seq.
+// On a seekable sequence, values skipped by seeking do not count.
+func Take[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+		i := 0
+		for v := range seq {
+			if !yield(v) {
+				return
+			}
+			i++
+			if i == n {
+				return
+			}
+		}
+	}
+}
+
+// Skip yields all but the first n values of seq.
+func Skip[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		i := 0
+		for v := range seq {
+			if i < n {
+				i++
+				continue
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Chunk yields the values of seq in slices of size n, the last one may be shorter.
+// Each chunk is a new slice.
+func Chunk[T any](seq iter.Seq[T], n int) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		if n <= 0 {
+			panic(fmt.Errorf("chunk size %d: must be positive", n))
+		}
+		chunk := make([]T, 0, n)
+		for v := range seq {
+			chunk = append(chunk, v)
+			if len(chunk) < n {
+				continue
+			}
+			if !yield(chunk) {
+				return
+			}
+			chunk = make([]T, 0, n)
+		}
+		if len(chunk) > 0 {
+			yield(chunk)
+		}
+	}
+}
+
+// Zip yields the values of left and right in pairs, until either runs out.
+func Zip[A, B any](left iter.Seq[A], right iter.Seq[B]) iter.Seq2[A, B] {
+	return func(yield func(A, B) bool) {
+		next, stop := iter.Pull(right)
+		defer stop()
+		for a := range left {
+			b, ok := next()
+			if !ok {
+				return
+			}
+			if !yield(a, b) {
+				return
+			}
+		}
+	}
+}
+
+// Dedup drops values that are equal to the one before them.
+func Dedup[T comparable](seq iter.Seq[T]) iter.Seq[T] {
+	return DedupFunc(seq, func(a, b T) bool {
+		return a == b
+	})
+}
+
+func DedupFunc[T any](seq iter.Seq[T], equal func(a, b T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		var last T
+		first := true
+		for v := range seq {
+			if !first && equal(last, v) {
+				continue
+			}
+			first = false
+			last = v
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// MergeSorted merges sorted sequences into one sorted sequence, keeping duplicates.
+// Equal values are yielded in the order of seqs.
+func MergeSorted[T any](less func(a, b T) bool, seqs ...iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		h := &mergeHeap[T]{less: less}
+		defer func() {
+			for _, item := range h.items {
+				item.stop()
+			}
+		}()
+		for i, seq := range seqs {
+			next, stop := iter.Pull(seq)
+			v, ok := next()
+			if !ok {
+				stop()
+				continue
+			}
+			h.items = append(h.items, &mergeItem[T]{value: v, index: i, next: next, stop: stop})
+		}
+		heap.Init(h)
+		for h.Len() > 0 {
+			item := h.items[0]
+			if !yield(item.value) {
+				return
+			}
+			v, ok := item.next()
+			if !ok {
+				item.stop()
+				heap.Pop(h)
+				continue
+			}
+			item.value = v
+			heap.Fix(h, 0)
+		}
+	}
+}
+
+type mergeItem[T any] struct {
+	value T
+	index int
+	next  func() (T, bool)
+	stop  func()
+}
+
+type mergeHeap[T any] struct {
+	items []*mergeItem[T]
+	less  func(a, b T) bool
+}
+
+func (h *mergeHeap[T]) Len() int {
+	return len(h.items)
+}
+
+func (h *mergeHeap[T]) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if h.less(a.value, b.value) {
+		return true
+	}
+	if h.less(b.value, a.value) {
+		return false
+	}
+	return a.index < b.index
+}
+
+func (h *mergeHeap[T]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+}
+
+func (h *mergeHeap[T]) Push(x any) {
+	h.items = append(h.items, x.(*mergeItem[T]))
+}
+
+func (h *mergeHeap[T]) Pop() any {
+	item := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return item
+}
+
+func Reduce[T, A any](seq iter.Seq[T], initial A, f func(A, T) A) A {
+	acc := initial
+	for v := range seq {
+		acc = f(acc, v)
+	}
+	return acc
+}
@@ -0,0 +1,97 @@
+package rang
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+	even := func(v int) bool { return v%2 == 0 }
+	require.Equal(t, []int{2, 4}, ToSlice(Filter(slices.Values([]int{1, 2, 3, 4, 5}), even)))
+	t.Run("seek", func(t *testing.T) {
+		var got []int
+		for v := range FilterSeek(FromSorted([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}), even) {
+			got = append(got, v.Value())
+			if v.Value() == 2 {
+				v.Seek(7)
+			}
+		}
+		require.Equal(t, []int{2, 8, 10}, got)
+	})
+}
+
+func TestTakeSkip(t *testing.T) {
+	seq := slices.Values([]int{1, 2, 3, 4, 5})
+	require.Equal(t, []int{1, 2}, ToSlice(Take(seq, 2)))
+	require.Equal(t, []int(nil), ToSlice(Take(seq, 0)))
+	require.Equal(t, []int{1, 2, 3, 4, 5}, ToSlice(Take(seq, 10)))
+	require.Equal(t, []int{4, 5}, ToSlice(Skip(seq, 3)))
+	require.Equal(t, []int(nil), ToSlice(Skip(seq, 10)))
+	require.Equal(t, []int{3}, ToSlice(Take(Skip(seq, 2), 1)))
+	t.Run("seek", func(t *testing.T) {
+		var got []int
+		for v := range Take(FromSorted([]int{1, 2, 3, 4, 5, 6}), 3) {
+			got = append(got, v.Value())
+			if v.Value() == 1 {
+				v.Seek(4)
+			}
+		}
+		require.Equal(t, []int{1, 4, 5}, got)
+	})
+}
+
+func TestChunk(t *testing.T) {
+	seq := slices.Values([]int{1, 2, 3, 4, 5})
+	require.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, ToSlice(Chunk(seq, 2)))
+	require.Equal(t, [][]int{{1, 2, 3, 4, 5}}, ToSlice(Chunk(seq, 5)))
+	require.Equal(t, [][]int(nil), ToSlice(Chunk(slices.Values([]int(nil)), 3)))
+	require.Panics(t, func() { ToSlice(Chunk(seq, 0)) })
+}
+
+func TestZip(t *testing.T) {
+	var left []int
+	var right []string
+	for a, b := range Zip(slices.Values([]int{1, 2, 3}), slices.Values([]string{"a", "b"})) {
+		left = append(left, a)
+		right = append(right, b)
+	}
+	require.Equal(t, []int{1, 2}, left)
+	require.Equal(t, []string{"a", "b"}, right)
+}
+
+func TestDedup(t *testing.T) {
+	require.Equal(t, []int{1, 2, 1, 3}, ToSlice(Dedup(slices.Values([]int{1, 1, 2, 2, 2, 1, 3, 3}))))
+	require.Equal(t, []int{0, 1}, ToSlice(Dedup(slices.Values([]int{0, 0, 1}))))
+	sameParity := func(a, b int) bool { return a%2 == b%2 }
+	require.Equal(t, []int{1, 2, 5}, ToSlice(DedupFunc(slices.Values([]int{1, 3, 2, 4, 5}), sameParity)))
+}
+
+func TestMergeSorted(t *testing.T) {
+	less := func(a, b int) bool { return a < b }
+	merged := MergeSorted(less,
+		slices.Values([]int{1, 4, 7}),
+		slices.Values([]int{2, 4, 8, 9}),
+		slices.Values([]int(nil)),
+		slices.Values([]int{0, 10}),
+	)
+	require.Equal(t, []int{0, 1, 2, 4, 4, 7, 8, 9, 10}, ToSlice(merged))
+	require.Equal(t, []int{0, 1}, ToSlice(Take(merged, 2)))
+	require.Equal(t, []int(nil), ToSlice(MergeSorted(less)))
+	t.Run("stable", func(t *testing.T) {
+		type pair struct{ key, src int }
+		byKey := func(a, b pair) bool { return a.key < b.key }
+		got := ToSlice(MergeSorted(byKey,
+			slices.Values([]pair{{1, 0}, {2, 0}}),
+			slices.Values([]pair{{1, 1}, {2, 1}}),
+		))
+		require.Equal(t, []pair{{1, 0}, {1, 1}, {2, 0}, {2, 1}}, got)
+	})
+}
+
+func TestReduce(t *testing.T) {
+	sum := Reduce(slices.Values([]int{1, 2, 3}), 0, func(acc, v int) int { return acc + v })
+	require.Equal(t, 6, sum)
+	require.Equal(t, "", Reduce(slices.Values([]int(nil)), "", func(acc string, v int) string { return acc + "x" }))
+}
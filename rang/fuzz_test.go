@@ -0,0 +1,93 @@
+package rang_test
+
+import (
+	"iter"
+	"math/rand"
+	"testing"
+
+	"github.com/PieterD/boevig/rang"
+	"github.com/PieterD/boevig/rang/rangtest"
+	"github.com/stretchr/testify/require"
+)
+
+func fuzzSets(seed int64, numSets uint8, size uint8, limit uint16) (*rand.Rand, [][]uint64) {
+	rnd := rand.New(rand.NewSource(seed))
+	sets := make([][]uint64, int(numSets%5)+1)
+	for i := range sets {
+		sets[i] = rangtest.RandomSet[uint64](rnd, rnd.Intn(int(size)+1), int(limit)+1)
+	}
+	return rnd, sets
+}
+
+func addSeeds(f *testing.F) {
+	f.Add(int64(1), uint8(1), uint8(10), uint16(20))
+	f.Add(int64(2), uint8(2), uint8(50), uint16(100))
+	f.Add(int64(3), uint8(3), uint8(200), uint16(300))
+	f.Add(int64(4), uint8(4), uint8(0), uint16(10))
+	f.Add(int64(5), uint8(1), uint8(255), uint16(65535))
+}
+
+func FuzzIntersect(f *testing.F) {
+	addSeeds(f)
+	o := rang.NewOrdered(func(a, b uint64) bool { return a < b })
+	f.Fuzz(func(t *testing.T, seed int64, numSets uint8, size uint8, limit uint16) {
+		rnd, sets := fuzzSets(seed, numSets, size, limit)
+		want := rangtest.Intersect(sets...)
+		var seqs []iter.Seq[rang.Seekable[uint64]]
+		var bitmaps []iter.Seq[rang.Seekable[uint64]]
+		for _, set := range sets {
+			seqs = append(seqs, rang.FromSorted(set))
+			bitmaps = append(bitmaps, rang.NewBitmap(set...).SeekSeq())
+		}
+		require.NoError(t, rangtest.CheckSeekSeq(o.Intersect(seqs...), want, rnd))
+		require.NoError(t, rangtest.CheckSeekSeq(o.Intersect(bitmaps...), want, rnd))
+		require.NoError(t, rangtest.CheckCursor(func() rang.Cursor[uint64] {
+			var cursors []rang.Cursor[uint64]
+			for _, set := range sets {
+				cursors = append(cursors, rang.SortedCursor(set))
+			}
+			return o.IntersectCursors(cursors...)
+		}, want, rnd))
+	})
+}
+
+func FuzzUnion(f *testing.F) {
+	addSeeds(f)
+	o := rang.NewOrdered(func(a, b uint64) bool { return a < b })
+	f.Fuzz(func(t *testing.T, seed int64, numSets uint8, size uint8, limit uint16) {
+		rnd, sets := fuzzSets(seed, numSets, size, limit)
+		want := rangtest.Union(sets...)
+		var seqs []iter.Seq[rang.Seekable[uint64]]
+		var plain []iter.Seq[uint64]
+		for _, set := range sets {
+			seqs = append(seqs, rang.FromSorted(set))
+			plain = append(plain, rang.UnSeek(rang.FromSorted(set)))
+		}
+		require.NoError(t, rangtest.CheckSeekSeq(o.Union(seqs...), want, rnd))
+		require.NoError(t, rangtest.CheckCursor(func() rang.Cursor[uint64] {
+			var cursors []rang.Cursor[uint64]
+			for _, set := range sets {
+				cursors = append(cursors, rang.SortedCursor(set))
+			}
+			return o.UnionCursors(cursors...)
+		}, want, rnd))
+		less := func(a, b uint64) bool { return a < b }
+		require.Equal(t, want, rang.ToSlice(rang.Dedup(rang.MergeSorted(less, plain...))))
+	})
+}
+
+func FuzzBitmap(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, seed int64, numSets uint8, size uint8, limit uint16) {
+		rnd, sets := fuzzSets(seed, numSets, size, limit)
+		for _, set := range sets {
+			b := rang.NewBitmap(set...)
+			require.NoError(t, rangtest.CheckSeekSeq(b.SeekSeq(), set, rnd))
+			require.NoError(t, rangtest.CheckSeekSeq(rang.FromSorted(set), set, rnd))
+			require.NoError(t, rangtest.CheckCursor(b.Cursor, set, rnd))
+			require.NoError(t, rangtest.CheckCursor(func() rang.Cursor[uint64] {
+				return rang.SortedCursor(set)
+			}, set, rnd))
+		}
+	})
+}
@@ -14,6 +14,16 @@ func NewOrdered[T any](less func(a, b T) bool) *Ordered[T] {
 	}
 }
 
+// Reverse returns an Ordered for descending sequences.
+// Seeking on its sequences moves to the next value at or below the sought value,
+// and its Intersect and Union combine descending sequences.
+func (o Ordered[T]) Reverse() *Ordered[T] {
+	less := o.less
+	return NewOrdered(func(a, b T) bool {
+		return less(b, a)
+	})
+}
+
 func (o Ordered[T]) newSeekable() Seekable[T] {
 	return Seekable[T]{
 		seek:      new(bool),
@@ -45,63 +55,95 @@ func (o Ordered[T]) SeekIterator(cons SeekConstructor[T]) iter.Seq[Seekable[T]]
 	}
 }
 
+// Intersect yields the values present in all seqs.
+// The first seq drives the intersection, the others are only sought to its values,
+// so it should be the smallest.
+// When all inputs have cursors, like sorted slices and bitmaps, IntersectCursors avoids the coroutines.
 func (o Ordered[T]) Intersect(seqs ...iter.Seq[Seekable[T]]) iter.Seq[Seekable[T]] {
 	return func(yield func(Seekable[T]) bool) {
-		holders := newSeqHolders(seqs)
+		holders := newSeqHolders(fallibles(seqs))
 		defer holders.Stop()
-		seekable := o.newSeekable()
-		for holders.AllAlive() {
-			maxValue, ok := holders.MaxValue()
-			if !ok {
-				return
-			}
-			if !holders.AllEqual(maxValue) {
-				holders.SeekAll(maxValue)
-				continue
-			}
-			seekable.value = maxValue
-			if !yield(seekable) {
-				return
-			}
-			if *seekable.seek {
-				holders.SeekAll(*seekable.seekValue)
-				*seekable.seek = false
-				continue
-			}
-			holders.Next()
-		}
+		_ = o.intersect(holders, yield)
 	}
 }
 
 func (o Ordered[T]) Union(seqs ...iter.Seq[Seekable[T]]) iter.Seq[Seekable[T]] {
 	return func(yield func(Seekable[T]) bool) {
-		holders := newSeqHolders(seqs)
+		holders := newSeqHolders(fallibles(seqs))
 		defer holders.Stop()
-		seekable := o.newSeekable()
-		for !holders.AllStopped() {
-			minValue, ok := holders.MinValue()
-			if !ok {
-				return
-			}
-			seekable.value = minValue
-			if !yield(seekable) {
-				return
+		_ = o.union(holders, yield)
+	}
+}
+
+func (o Ordered[T]) intersect(holders seqHolders[T], yield func(Seekable[T]) bool) error {
+	if len(holders) == 0 {
+		return nil
+	}
+	seekable := o.newSeekable()
+	driver := holders[0]
+	for holders.AllAlive() {
+		candidate := driver.Value.Value()
+		agreed := true
+		for _, holder := range holders[1:] {
+			if o.less(holder.Value.Value(), candidate) {
+				holder.Seek(candidate)
+				if !holder.Alive() {
+					return holders.Err()
+				}
 			}
-			if *seekable.seek {
-				holders.SeekAll(*seekable.seekValue)
-				*seekable.seek = false
-				continue
+			if v := holder.Value.Value(); o.less(candidate, v) {
+				driver.Seek(v)
+				agreed = false
+				break
 			}
-			holders.NextEqual(minValue)
 		}
+		if !agreed {
+			continue
+		}
+		seekable.value = candidate
+		if !yield(seekable) {
+			return nil
+		}
+		if *seekable.seek {
+			holders.SeekAll(*seekable.seekValue)
+			*seekable.seek = false
+			continue
+		}
+		holders.Next()
+	}
+	return holders.Err()
+}
+
+func (o Ordered[T]) union(holders seqHolders[T], yield func(Seekable[T]) bool) error {
+	seekable := o.newSeekable()
+	for !holders.AllStopped() {
+		if err := holders.Err(); err != nil {
+			return err
+		}
+		minValue, ok := holders.MinValue()
+		if !ok {
+			return nil
+		}
+		seekable.value = minValue
+		if !yield(seekable) {
+			return nil
+		}
+		if *seekable.seek {
+			holders.SeekAll(*seekable.seekValue)
+			*seekable.seek = false
+			continue
+		}
+		holders.NextEqual(minValue)
 	}
+	return holders.Err()
 }
 
 type seqHolder[T any] struct {
-	Seq      iter.Seq[Seekable[T]]
-	PullNext func() (Seekable[T], bool)
+	Seq      iter.Seq2[Seekable[T], error]
+	PullNext func() (Seekable[T], error, bool)
 	PullStop func()
 	Value    Seekable[T]
+	Err      error
 }
 
 func (sh *seqHolder[T]) Alive() bool {
@@ -112,16 +154,23 @@ func (sh *seqHolder[T]) Next() {
 	if !sh.Alive() {
 		return
 	}
-	v, ok := sh.PullNext()
+	v, err, ok := sh.PullNext()
 	if !ok {
 		sh.Stop()
 		return
 	}
+	if err != nil {
+		sh.Err = err
+		sh.Stop()
+		return
+	}
 	sh.Value = v
 }
 
+// Seek leaves holders that are already at or past to alone,
+// they would skip their current value otherwise.
 func (sh *seqHolder[T]) Seek(to T) {
-	if !sh.Alive() {
+	if !sh.Alive() || !sh.Value.Less()(sh.Value.Value(), to) {
 		return
 	}
 	sh.Value.Seek(to)
@@ -140,14 +189,14 @@ func (sh *seqHolder[T]) Stop() {
 
 type seqHolders[T any] []*seqHolder[T]
 
-func newSeqHolders[T any](seqs []iter.Seq[Seekable[T]]) seqHolders[T] {
+func newSeqHolders[T any](seqs []iter.Seq2[Seekable[T], error]) seqHolders[T] {
 	var holders []*seqHolder[T]
 	for _, seq := range seqs {
 		holder := &seqHolder[T]{
 			Seq:   seq,
 			Value: Seekable[T]{},
 		}
-		holder.PullNext, holder.PullStop = iter.Pull(seq)
+		holder.PullNext, holder.PullStop = iter.Pull2(seq)
 		holder.Next()
 		holders = append(holders, holder)
 	}
@@ -160,6 +209,15 @@ func (holders seqHolders[T]) Stop() {
 	}
 }
 
+func (holders seqHolders[T]) Err() error {
+	for _, holder := range holders {
+		if holder.Err != nil {
+			return holder.Err
+		}
+	}
+	return nil
+}
+
 func (holders seqHolders[T]) AllAlive() bool {
 	for _, holder := range holders {
 		if !holder.Alive() {
@@ -178,28 +236,6 @@ func (holders seqHolders[T]) AllStopped() bool {
 	return true
 }
 
-func (holders seqHolders[T]) MaxValue() (T, bool) {
-	var highest T
-	first := true
-	for _, holder := range holders {
-		if !holder.Alive() {
-			continue
-		}
-		v := holder.Value.Value()
-		if first {
-			highest = v
-			first = false
-			continue
-		}
-		if holder.Value.Less()(highest, v) {
-			highest = v
-		}
-	}
-	if first {
-		return highest, false
-	}
-	return highest, true
-}
 func (holders seqHolders[T]) MinValue() (T, bool) {
 	var lowest T
 	first := true
@@ -223,20 +259,6 @@ func (holders seqHolders[T]) MinValue() (T, bool) {
 	return lowest, true
 }
 
-func (holders seqHolders[T]) AllEqual(comparValue T) bool {
-	for _, holder := range holders {
-		if !holder.Alive() {
-			return false
-		}
-		v := holder.Value.Value()
-		less := holder.Value.Less()
-		if less(comparValue, v) || less(v, comparValue) {
-			return false
-		}
-	}
-	return true
-}
-
 func (holders seqHolders[T]) NextEqual(comparValue T) {
 	for _, holder := range holders {
 		if !holder.Alive() {
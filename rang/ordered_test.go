@@ -214,3 +214,63 @@ func (it *TestSearcher) Search(first *int) iter.Seq[int] {
 		it.tree.AscendGreaterOrEqual(*first, visitor)
 	}
 }
+
+func (it *TestSearcher) ReverseSearch(first *int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		visitor := func(item int) bool {
+			return yield(item)
+		}
+		if first == nil {
+			it.tree.Descend(visitor)
+			return
+		}
+		it.tree.DescendLessOrEqual(*first, visitor)
+	}
+}
+
+func TestOrdered_Reverse(t *testing.T) {
+	less := func(a, b int) bool {
+		return a < b
+	}
+	o := NewOrdered(less).Reverse()
+	t.Run("seek iterator", func(t *testing.T) {
+		seq := o.SeekIterator(NewTestSearcher(1, 2, 3, 4, 5, 6, 7, 8).ReverseSearch)
+		var got []int
+		for v := range seq {
+			if v.Value() == 7 {
+				v.Seek(4)
+				continue
+			}
+			if v.Value() == 3 {
+				v.Seek(5)
+			}
+			got = append(got, v.Value())
+		}
+		require.Equal(t, []int{8, 4, 3, 2, 1}, got)
+	})
+	t.Run("intersect", func(t *testing.T) {
+		left := o.SeekIterator(NewTestSearcher(1, 2, 3, 4, 6, 8).ReverseSearch)
+		right := o.SeekIterator(NewTestSearcher(2, 4, 5, 6, 7).ReverseSearch)
+		require.Equal(t, []int{6, 4, 2}, ToSlice(UnSeek(o.Intersect(left, right))))
+	})
+	t.Run("union", func(t *testing.T) {
+		left := o.SeekIterator(NewTestSearcher(1, 3, 8).ReverseSearch)
+		right := o.SeekIterator(NewTestSearcher(2, 3, 5).ReverseSearch)
+		require.Equal(t, []int{8, 5, 3, 2, 1}, ToSlice(UnSeek(o.Union(left, right))))
+	})
+}
+
+func TestOrdered_UnionSeek(t *testing.T) {
+	o := NewOrdered(func(a, b int) bool { return a < b })
+	var got []int
+	seeked := false
+	for v := range o.Union(FromSorted([]int{0, 2, 6}), FromSorted([]int{1, 5, 7})) {
+		got = append(got, v.Value())
+		if v.Value() == 2 && !seeked {
+			// The second seq is already past 4, it must not be moved on.
+			v.Seek(4)
+			seeked = true
+		}
+	}
+	require.Equal(t, []int{0, 1, 2, 5, 6, 7}, got)
+}
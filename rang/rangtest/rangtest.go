@@ -0,0 +1,226 @@
+// Package rangtest checks implementations of rang's seekable sequences and cursors
+// against a naive model of sorted sets.
+package rangtest
+
+import (
+	"fmt"
+	"iter"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/PieterD/boevig/rang"
+)
+
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// rounds is the number of random seek patterns each check runs.
+const rounds = 50
+
+// CheckSeekSeq verifies that seq yields exactly the sorted values in want,
+// also when it is iterated again, stopped early, or sought with random targets.
+// Seeking below the current value is ignored, seeking to it yields it again.
+func CheckSeekSeq[T Integer](seq iter.Seq[rang.Seekable[T]], want []T, rnd *rand.Rand) error {
+	for _, pass := range []string{"iterating", "iterating again"} {
+		if got := rang.ToSlice(rang.UnSeek(seq)); !slices.Equal(got, want) {
+			return fmt.Errorf("%s: got %v, want %v", pass, got, want)
+		}
+	}
+	for i := 0; i < rounds; i++ {
+		if err := checkSeeks(seq, want, rnd); err != nil {
+			return fmt.Errorf("seek round %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func checkSeeks[T Integer](seq iter.Seq[rang.Seekable[T]], want []T, rnd *rand.Rand) error {
+	var steps []string
+	fail := func(format string, args ...any) error {
+		return fmt.Errorf("after [%s]: %s", strings.Join(steps, " "), fmt.Sprintf(format, args...))
+	}
+	pos := 0
+	for sv := range seq {
+		v := sv.Value()
+		if pos >= len(want) {
+			return fail("yielded %v past the end", v)
+		}
+		if v != want[pos] {
+			return fail("yielded %v, want %v", v, want[pos])
+		}
+		switch rnd.Intn(8) {
+		case 0:
+			return nil
+		case 1, 2, 3:
+			target := randomTarget(want, v, rnd)
+			if target < v {
+				steps = append(steps, fmt.Sprintf("seek(%v, ignored)", target))
+				sv.Seek(target)
+				pos++
+				continue
+			}
+			steps = append(steps, fmt.Sprintf("seek(%v)", target))
+			sv.Seek(target)
+			pos = lowerBound(want, target)
+		default:
+			steps = append(steps, "next")
+			pos++
+		}
+	}
+	if pos < len(want) {
+		return fail("stopped, want %v next", want[pos])
+	}
+	return nil
+}
+
+// CheckCursor verifies that new cursors walk exactly the sorted values in want,
+// under random sequences of Next and Seek.
+func CheckCursor[T Integer](newCursor func() rang.Cursor[T], want []T, rnd *rand.Rand) error {
+	c := newCursor()
+	var got []T
+	for ok := c.Next(); ok; ok = c.Next() {
+		got = append(got, c.Value())
+	}
+	if !slices.Equal(got, want) {
+		return fmt.Errorf("iterating: got %v, want %v", got, want)
+	}
+	if c.Next() {
+		return fmt.Errorf("iterating: Next after the end returned true")
+	}
+	for i := 0; i < rounds; i++ {
+		if err := checkCursor(newCursor(), want, rnd); err != nil {
+			return fmt.Errorf("cursor round %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func checkCursor[T Integer](c rang.Cursor[T], want []T, rnd *rand.Rand) error {
+	var steps []string
+	fail := func(format string, args ...any) error {
+		return fmt.Errorf("after [%s]: %s", strings.Join(steps, " "), fmt.Sprintf(format, args...))
+	}
+	// pos is the index of the current value in want, -1 before the start.
+	pos := -1
+	for len(steps) < 2*len(want)+4 {
+		var ok bool
+		if rnd.Intn(2) == 0 {
+			steps = append(steps, "next")
+			ok = c.Next()
+			pos = min(pos+1, len(want))
+		} else {
+			var current T
+			if pos >= 0 && pos < len(want) {
+				current = want[pos]
+			}
+			target := randomTarget(want, current, rnd)
+			steps = append(steps, fmt.Sprintf("seek(%v)", target))
+			ok = c.Seek(target)
+			if pos < len(want) {
+				pos = max(pos, lowerBound(want, target))
+			}
+		}
+		if wantOK := pos < len(want); ok != wantOK {
+			return fail("got %v, want %v", ok, wantOK)
+		}
+		if !ok {
+			return nil
+		}
+		if v := c.Value(); v != want[pos] {
+			return fail("at %v, want %v", v, want[pos])
+		}
+	}
+	return nil
+}
+
+// randomTarget picks a value near current, or near one of the wanted values.
+func randomTarget[T Integer](want []T, current T, rnd *rand.Rand) T {
+	base := current
+	if len(want) > 0 && rnd.Intn(2) == 0 {
+		base = want[rnd.Intn(len(want))]
+	}
+	return offset(base, rnd.Intn(7)-2)
+}
+
+// offset adds d to v, without wrapping around.
+func offset[T Integer](v T, d int) T {
+	w := v
+	for ; d > 0; d-- {
+		if w+1 < w {
+			return w
+		}
+		w++
+	}
+	for ; d < 0; d++ {
+		if w-1 > w {
+			return w
+		}
+		w--
+	}
+	return w
+}
+
+func lowerBound[T Integer](want []T, target T) int {
+	return sort.Search(len(want), func(i int) bool {
+		return want[i] >= target
+	})
+}
+
+// RandomSet returns up to size distinct values below limit, in ascending order.
+func RandomSet[T Integer](rnd *rand.Rand, size int, limit int) []T {
+	if limit <= 0 {
+		return nil
+	}
+	seen := make(map[T]bool)
+	var set []T
+	for i := 0; i < size; i++ {
+		v := T(rnd.Intn(limit))
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		set = append(set, v)
+	}
+	slices.Sort(set)
+	return set
+}
+
+// Intersect is the naive model of an intersection of sorted sets.
+func Intersect[T Integer](sets ...[]T) []T {
+	if len(sets) == 0 {
+		return nil
+	}
+	counts := make(map[T]int)
+	for _, set := range sets {
+		for _, v := range set {
+			counts[v]++
+		}
+	}
+	var result []T
+	for _, v := range sets[0] {
+		if counts[v] == len(sets) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// Union is the naive model of a union of sorted sets.
+func Union[T Integer](sets ...[]T) []T {
+	seen := make(map[T]bool)
+	var result []T
+	for _, set := range sets {
+		for _, v := range set {
+			if !seen[v] {
+				seen[v] = true
+				result = append(result, v)
+			}
+		}
+	}
+	slices.Sort(result)
+	return result
+}
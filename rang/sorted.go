@@ -0,0 +1,86 @@
+package rang
+
+import (
+	"cmp"
+	"iter"
+)
+
+// FromSorted yields the values of a sorted slice.
+// Seeking gallops forward from the current position instead of skipping values one by one.
+func FromSorted[T cmp.Ordered](s []T) iter.Seq[Seekable[T]] {
+	return FromSortedFunc(s, cmp.Less[T])
+}
+
+func FromSortedFunc[T any](s []T, less func(a, b T) bool) iter.Seq[Seekable[T]] {
+	return NewOrdered(less).CursorSeq(func() Cursor[T] {
+		return SortedCursorFunc(s, less)
+	})
+}
+
+// intersectSorted intersects sorted slices with a leapfrog join.
+func intersectSorted[T any](less func(a, b T) bool, slices ...[]T) []T {
+	if len(slices) == 0 {
+		return nil
+	}
+	var result []T
+	positions := make([]int, len(slices))
+	for {
+		if positions[0] == len(slices[0]) {
+			return result
+		}
+		candidate := slices[0][positions[0]]
+		agreed := true
+		for i := 1; i < len(slices); i++ {
+			positions[i] = gallop(slices[i], positions[i], candidate, less)
+			if positions[i] == len(slices[i]) {
+				return result
+			}
+			if v := slices[i][positions[i]]; less(candidate, v) {
+				positions[0] = gallop(slices[0], positions[0], v, less)
+				agreed = false
+				break
+			}
+		}
+		if !agreed {
+			continue
+		}
+		result = append(result, candidate)
+		for i := range positions {
+			positions[i]++
+		}
+	}
+}
+
+// gallop returns the first index at or after from whose value is not less than target.
+func gallop[T any](s []T, from int, target T, less func(a, b T) bool) int {
+	return gallopIndex(from, len(s), func(i int) bool {
+		return less(s[i], target)
+	})
+}
+
+// gallopIndex returns the first index in [from, end) for which before is false, or end.
+// It doubles its step until it overshoots, then binary searches the last step.
+func gallopIndex(from, end int, before func(i int) bool) int {
+	if from >= end || !before(from) {
+		return from
+	}
+	lo, step := from, 1
+	hi := from + step
+	for hi < end && before(hi) {
+		lo = hi
+		step *= 2
+		hi = from + step
+	}
+	if hi > end {
+		hi = end
+	}
+	for lo+1 < hi {
+		mid := lo + (hi-lo)/2
+		if before(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi
+}
@@ -0,0 +1,146 @@
+package rang
+
+import (
+	"iter"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromSorted(t *testing.T) {
+	t.Run("simple", func(t *testing.T) {
+		require.Equal(t, []int{1, 3, 5}, ToSlice(UnSeek(FromSorted([]int{1, 3, 5}))))
+		require.Equal(t, []int(nil), ToSlice(UnSeek(FromSorted([]int(nil)))))
+	})
+	t.Run("seek", func(t *testing.T) {
+		s := make([]int, 0, 100)
+		for i := 0; i < 100; i++ {
+			s = append(s, i*2)
+		}
+		var got []int
+		for v := range FromSorted(s) {
+			switch v.Value() {
+			case 0:
+				v.Seek(7)
+				continue
+			case 10:
+				v.Seek(150)
+				continue
+			case 152:
+				v.Seek(1000)
+				continue
+			}
+			got = append(got, v.Value())
+		}
+		require.Equal(t, []int{8, 150}, got)
+	})
+	t.Run("iterate twice", func(t *testing.T) {
+		seq := FromSorted([]int{1, 2, 3})
+		for v := range seq {
+			if v.Value() < 3 {
+				v.Seek(3)
+			}
+		}
+		require.Equal(t, []int{1, 2, 3}, ToSlice(UnSeek(seq)))
+	})
+}
+
+func TestGallop(t *testing.T) {
+	less := func(a, b int) bool { return a < b }
+	s := []int{1, 3, 5, 7, 9, 11, 13, 15, 17, 19}
+	for from := 0; from <= len(s); from++ {
+		for target := 0; target <= 20; target++ {
+			expected := from + sort.SearchInts(s[from:], target)
+			require.Equal(t, expected, gallop(s, from, target, less), "from %d target %d", from, target)
+		}
+	}
+}
+
+func TestIntersectSorted(t *testing.T) {
+	less := func(a, b int) bool { return a < b }
+	require.Equal(t, []int{3, 4}, intersectSorted(less, []int{1, 2, 3, 4}, []int{3, 4, 5, 6}))
+	require.Equal(t, []int{6}, intersectSorted(less, []int{6}, []int{1, 2, 3, 4, 5, 6, 7, 8}, []int{0, 6, 9}))
+	require.Equal(t, []int(nil), intersectSorted(less, []int{1, 2}, nil))
+	require.Equal(t, []int(nil), intersectSorted[int](less))
+}
+
+func TestOrdered_IntersectSorted(t *testing.T) {
+	o := NewOrdered(func(a, b int) bool { return a < b })
+	rnd := rand.New(rand.NewSource(3))
+	left := randomSorted(rnd, 100, 10000)
+	right := randomSorted(rnd, 5000, 10000)
+	expected := intersectSorted(o.less, left, right)
+	require.Equal(t, expected, ToSlice(UnSeek(o.Intersect(FromSorted(left), FromSorted(right)))))
+	require.Equal(t, expected, cursorSlice(o.IntersectCursors(SortedCursor(left), SortedCursor(right))))
+
+	t.Run("wrapped", func(t *testing.T) {
+		s := []int{1, 2, 3, 4, 5, 6}
+		even := func(v int) bool { return v%2 == 0 }
+		require.Equal(t, []int{2, 4, 6}, ToSlice(UnSeek(o.Intersect(FromSorted(s), FilterSeek(FromSorted(s), even)))))
+		require.Equal(t, []int{1, 2}, ToSlice(UnSeek(o.Intersect(Take(FromSorted(s), 2), FromSorted(s)))))
+		require.Equal(t, []int{5, 6}, ToSlice(UnSeek(o.Intersect(FromSorted(s), Skip(FromSorted(s), 4)))))
+	})
+	t.Run("user wrapper", func(t *testing.T) {
+		// A wrapper written outside rang passes the Seekables of its source on as they are.
+		evens := func(seq iter.Seq[Seekable[uint32]]) iter.Seq[Seekable[uint32]] {
+			return func(yield func(Seekable[uint32]) bool) {
+				for v := range seq {
+					if v.Value()%2 == 0 && !yield(v) {
+						return
+					}
+				}
+			}
+		}
+		b := NewBitmap[uint32](1, 2, 3, 4, 5, 6, 7, 8)
+		o := NewOrdered(func(a, b uint32) bool { return a < b })
+		require.Equal(t, []uint32{2, 4, 6, 8}, ToSlice(UnSeek(o.Intersect(evens(b.SeekSeq()), b.SeekSeq()))))
+		require.Equal(t, []uint32{2, 4, 6, 8}, ToSlice(UnSeek(o.Intersect(b.SeekSeq(), evens(b.SeekSeq())))))
+	})
+	t.Run("seek", func(t *testing.T) {
+		var got []int
+		for v := range o.Intersect(FromSorted([]int{1, 3, 5, 7, 9}), FromSorted([]int{1, 5, 7, 8, 9})) {
+			got = append(got, v.Value())
+			if v.Value() == 1 {
+				v.Seek(6)
+			}
+		}
+		require.Equal(t, []int{1, 7, 9}, got)
+	})
+}
+
+func BenchmarkIntersect_Sorted(b *testing.B) {
+	rnd := rand.New(rand.NewSource(4))
+	small := randomSorted(rnd, 100, 1000000)
+	large := randomSorted(rnd, 100000, 1000000)
+	o := NewOrdered(func(a, b int) bool { return a < b })
+	b.Run("cursors", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			c := o.IntersectCursors(SortedCursor(small), SortedCursor(large))
+			for c.Next() {
+			}
+		}
+	})
+	b.Run("sequences", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for range o.Intersect(FromSorted(small), FromSorted(large)) {
+			}
+		}
+	})
+}
+
+func randomSorted(rnd *rand.Rand, size int, max int) []int {
+	seen := make(map[int]bool)
+	var s []int
+	for len(s) < size {
+		v := rnd.Intn(max)
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		s = append(s, v)
+	}
+	sort.Ints(s)
+	return s
+}
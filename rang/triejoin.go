@@ -0,0 +1,186 @@
+package rang
+
+import (
+	"fmt"
+	"iter"
+	"sort"
+)
+
+// TrieIterator walks a trie of sorted keys one level at a time.
+// It starts above the first level, Open descends to the first child of the current key,
+// and Up returns to the parent key.
+type TrieIterator[T any] interface {
+	Open()
+	Up()
+	Next()
+	// Seek moves to the first key at the current level that is not less than the given one.
+	Seek(key T)
+	Key() T
+	AtEnd() bool
+}
+
+// SliceTrie is a TrieIterator over tuples of equal length, one level per column.
+type SliceTrie[T any] struct {
+	tuples [][]T
+	less   func(a, b T) bool
+	// levels holds, per opened level, the current position and the end of the range that shares its parent's prefix.
+	levels []trieLevel
+}
+
+type trieLevel struct {
+	pos int
+	end int
+}
+
+func NewSliceTrie[T any](less func(a, b T) bool, tuples [][]T) *SliceTrie[T] {
+	sorted := append([][]T(nil), tuples...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return tupleLess(sorted[i], sorted[j], less)
+	})
+	return &SliceTrie[T]{
+		tuples: sorted,
+		less:   less,
+	}
+}
+
+func (t *SliceTrie[T]) Open() {
+	start, end := 0, len(t.tuples)
+	if depth := len(t.levels); depth > 0 {
+		level := t.levels[depth-1]
+		start, end = level.pos, t.keyEnd(depth-1, level.pos, level.end)
+	}
+	t.levels = append(t.levels, trieLevel{pos: start, end: end})
+}
+
+func (t *SliceTrie[T]) Up() {
+	t.levels = t.levels[:len(t.levels)-1]
+}
+
+func (t *SliceTrie[T]) Next() {
+	depth := len(t.levels) - 1
+	level := &t.levels[depth]
+	level.pos = t.keyEnd(depth, level.pos, level.end)
+}
+
+func (t *SliceTrie[T]) Seek(key T) {
+	depth := len(t.levels) - 1
+	level := &t.levels[depth]
+	level.pos = gallopIndex(level.pos, level.end, func(i int) bool {
+		return t.less(t.tuples[i][depth], key)
+	})
+}
+
+func (t *SliceTrie[T]) Key() T {
+	depth := len(t.levels) - 1
+	return t.tuples[t.levels[depth].pos][depth]
+}
+
+func (t *SliceTrie[T]) AtEnd() bool {
+	level := t.levels[len(t.levels)-1]
+	return level.pos >= level.end
+}
+
+// keyEnd returns the end of the run of tuples sharing the key at pos.
+func (t *SliceTrie[T]) keyEnd(depth, pos, end int) int {
+	key := t.tuples[pos][depth]
+	return gallopIndex(pos, end, func(i int) bool {
+		return !t.less(key, t.tuples[i][depth])
+	})
+}
+
+func tupleLess[T any](a, b []T, less func(a, b T) bool) bool {
+	for i := range a {
+		if less(a[i], b[i]) {
+			return true
+		}
+		if less(b[i], a[i]) {
+			return false
+		}
+	}
+	return false
+}
+
+// TrieAtom binds the levels of a trie to join variables.
+// Vars must be increasing, so the trie's columns have to be stored in variable order.
+type TrieAtom[T any] struct {
+	Trie TrieIterator[T]
+	Vars []int
+}
+
+// TrieJoin is a leapfrog triejoin, a worst-case optimal join of all atoms on numVars variables.
+// It yields a binding for every variable, in variable order.
+// The yielded slice is reused between iterations.
+func (o Ordered[T]) TrieJoin(numVars int, atoms ...TrieAtom[T]) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		participants := make([][]TrieIterator[T], numVars)
+		for _, atom := range atoms {
+			for i, v := range atom.Vars {
+				if v < 0 || v >= numVars || (i > 0 && v <= atom.Vars[i-1]) {
+					panic(fmt.Errorf("trie join: atom variables %v must be increasing and below %d", atom.Vars, numVars))
+				}
+				participants[v] = append(participants[v], atom.Trie)
+			}
+		}
+		for v, its := range participants {
+			if len(its) == 0 {
+				panic(fmt.Errorf("trie join: variable %d is not bound by any atom", v))
+			}
+		}
+		binding := make([]T, numVars)
+		o.trieJoin(participants, 0, binding, yield)
+	}
+}
+
+func (o Ordered[T]) trieJoin(participants [][]TrieIterator[T], depth int, binding []T, yield func([]T) bool) bool {
+	if depth == len(participants) {
+		return yield(binding)
+	}
+	its := participants[depth]
+	for _, it := range its {
+		it.Open()
+	}
+	defer func() {
+		for _, it := range its {
+			it.Up()
+		}
+	}()
+	for {
+		key, ok := o.leapfrog(its)
+		if !ok {
+			return true
+		}
+		binding[depth] = key
+		if !o.trieJoin(participants, depth+1, binding, yield) {
+			return false
+		}
+		its[0].Next()
+	}
+}
+
+// leapfrog seeks the iterators until they all agree on a key.
+func (o Ordered[T]) leapfrog(its []TrieIterator[T]) (T, bool) {
+	var zero T
+	for {
+		for _, it := range its {
+			if it.AtEnd() {
+				return zero, false
+			}
+		}
+		highest := its[0].Key()
+		for _, it := range its[1:] {
+			if k := it.Key(); o.less(highest, k) {
+				highest = k
+			}
+		}
+		agreed := true
+		for _, it := range its {
+			if o.less(it.Key(), highest) {
+				it.Seek(highest)
+				agreed = false
+			}
+		}
+		if agreed {
+			return highest, true
+		}
+	}
+}
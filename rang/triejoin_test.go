@@ -0,0 +1,110 @@
+package rang
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSliceTrie(t *testing.T) {
+	less := func(a, b int) bool { return a < b }
+	trie := NewSliceTrie(less, [][]int{{2, 1}, {1, 3}, {1, 2}, {3, 4}, {2, 5}})
+	trie.Open()
+	require.Equal(t, 1, trie.Key())
+	trie.Open()
+	require.Equal(t, 2, trie.Key())
+	trie.Next()
+	require.Equal(t, 3, trie.Key())
+	trie.Next()
+	require.True(t, trie.AtEnd())
+	trie.Up()
+	trie.Next()
+	require.Equal(t, 2, trie.Key())
+	trie.Open()
+	trie.Seek(3)
+	require.Equal(t, 5, trie.Key())
+	trie.Up()
+	trie.Seek(3)
+	require.Equal(t, 3, trie.Key())
+	trie.Seek(4)
+	require.True(t, trie.AtEnd())
+}
+
+func TestOrdered_TrieJoin(t *testing.T) {
+	less := func(a, b int) bool { return a < b }
+	o := NewOrdered(less)
+	t.Run("triangles", func(t *testing.T) {
+		edges := [][]int{{1, 2}, {2, 3}, {1, 3}, {3, 4}, {2, 4}, {1, 5}}
+		// R(a,b), S(b,c), T(a,c)
+		var got [][]int
+		for binding := range o.TrieJoin(3,
+			TrieAtom[int]{Trie: NewSliceTrie(less, edges), Vars: []int{0, 1}},
+			TrieAtom[int]{Trie: NewSliceTrie(less, edges), Vars: []int{1, 2}},
+			TrieAtom[int]{Trie: NewSliceTrie(less, edges), Vars: []int{0, 2}},
+		) {
+			got = append(got, append([]int(nil), binding...))
+		}
+		require.Equal(t, [][]int{{1, 2, 3}, {2, 3, 4}}, got)
+	})
+	t.Run("break", func(t *testing.T) {
+		trie := NewSliceTrie(less, [][]int{{1}, {2}, {3}})
+		seq := o.TrieJoin(1, TrieAtom[int]{Trie: trie, Vars: []int{0}})
+		for range seq {
+			break
+		}
+		var got []int
+		for binding := range seq {
+			got = append(got, binding[0])
+		}
+		require.Equal(t, []int{1, 2, 3}, got)
+	})
+	t.Run("random against nested loops", func(t *testing.T) {
+		rnd := rand.New(rand.NewSource(5))
+		random := func() [][]int {
+			var tuples [][]int
+			for i := 0; i < 200; i++ {
+				tuples = append(tuples, []int{rnd.Intn(20), rnd.Intn(20)})
+			}
+			return tuples
+		}
+		r, s := random(), random()
+		has := func(tuples [][]int, a, b int) bool {
+			for _, tuple := range tuples {
+				if tuple[0] == a && tuple[1] == b {
+					return true
+				}
+			}
+			return false
+		}
+		// R(a,b), S(b,a): pairs present in both directions.
+		var expected [][]int
+		for a := 0; a < 20; a++ {
+			for b := 0; b < 20; b++ {
+				if has(r, a, b) && has(s, b, a) {
+					expected = append(expected, []int{a, b})
+				}
+			}
+		}
+		swapped := make([][]int, 0, len(s))
+		for _, tuple := range s {
+			swapped = append(swapped, []int{tuple[1], tuple[0]})
+		}
+		var got [][]int
+		for binding := range o.TrieJoin(2,
+			TrieAtom[int]{Trie: NewSliceTrie(less, r), Vars: []int{0, 1}},
+			TrieAtom[int]{Trie: NewSliceTrie(less, swapped), Vars: []int{0, 1}},
+		) {
+			got = append(got, append([]int(nil), binding...))
+		}
+		sort.Slice(expected, func(i, j int) bool { return tupleLess(expected[i], expected[j], less) })
+		require.Equal(t, expected, got)
+	})
+	t.Run("unbound variable", func(t *testing.T) {
+		require.Panics(t, func() {
+			for range o.TrieJoin(2, TrieAtom[int]{Trie: NewSliceTrie(less, [][]int{{1}}), Vars: []int{0}}) {
+			}
+		})
+	})
+}